@@ -0,0 +1,174 @@
+package astar
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+)
+
+// Solution is a path found by SearchAnytime.
+type Solution[S comparable] struct {
+	Path []S
+	Cost float64
+
+	// Estimate weight the path has been found with.
+	Weight float64
+
+	// Suboptimality bound: Cost is at most Bound times the shortest
+	// path cost. Bound = 1 means the path is proven to be the shortest.
+	Bound float64
+
+	// Total number of states explored so far, by all the searches.
+	Expanded int
+
+	// Time since the start of SearchAnytime.
+	Elapsed time.Duration
+}
+
+// SearchAnytime finds a path quickly and then keeps improving it.
+// It runs a series of weighted A* searches, which multiply Heuristic()
+// by weight first, then by a weight lowered by step each time, and then by 1.
+// A larger weight makes the search greedier: it explores fewer states,
+// but the path found may be up to weight times longer than the shortest one.
+// Each search only looks for paths cheaper than the best one found so far.
+//
+// Every time a cheaper path is found, it is passed to improved along with
+// its proven suboptimality bound, and so is the last path once it is proven
+// to be the shortest one. SearchAnytime stops at that point, or when ctx
+// is done. In the latter case it returns the best path found so far along
+// with ctx.Err(). If no path exists, ErrNotFound is returned. improved may
+// be nil.
+//
+// The bounds are only valid if Heuristic() is admissible. A panic in one
+// of p's methods is returned as a *PanicError along with the best path
+// found so far. States are compared with ==: ErrKeysUnsupported is
+// returned if p is a Keyer.
+func SearchAnytime[S comparable](ctx context.Context, p Problem[S], weight, step float64, improved func(Solution[S])) (sol Solution[S], err error) {
+	begin := time.Now()
+	done := ctx.Done()
+
+	best := Solution[S]{Cost: math.Inf(1), Bound: math.Inf(1)}
+	expanded := 0
+
+	if err := noKeys(&config{}, p); err != nil {
+		return best, err
+	}
+
+	// Return panics in p's methods as errors. Every return is of best.
+	g := newGuard(p)
+	p = g
+	defer func() { sol = best }()
+	defer g.catch(&err)
+
+	lower := func(w float64) float64 {
+		if step <= 0 {
+			return 1
+		}
+		return math.Max(w-step, 1)
+	}
+
+	for w := math.Max(weight, 1); ; w = lower(w) {
+		start := &state[S]{state: p.Start()}
+		start.estimate = w * p.Heuristic(start.state)
+		pq := states[S]{}
+		heap.Push(&pq, start)
+		seen := map[S]*state[S]{start.state: start}
+
+		var goal *state[S]
+		for !pq.Empty() {
+			select {
+			case <-done:
+				return best, ctx.Err()
+			default:
+			}
+
+			current := heap.Pop(&pq).(*state[S])
+			current.explored = true
+			expanded++
+
+			if p.IsGoal(current.state) {
+				goal = current
+				break
+			}
+
+			for _, edge := range p.Neighbors(current.state) {
+				cost := current.cost + edge.Cost
+
+				succ, ok := seen[edge.To]
+				if ok && cost >= succ.cost {
+					continue
+				}
+
+				var estimate float64
+				if ok {
+					estimate = succ.estimate
+				} else {
+					estimate = w * p.Heuristic(edge.To)
+				}
+
+				// Only paths cheaper than the best one are of interest.
+				if cost+estimate/w >= best.Cost {
+					continue
+				}
+
+				if !ok {
+					succ = &state[S]{state: edge.To, estimate: estimate}
+					seen[edge.To] = succ
+				}
+
+				succ.cost, succ.parent = cost, current
+				switch {
+				case !ok || succ.explored:
+					// Reopen explored states to keep the bounds valid.
+					succ.explored = false
+					heap.Push(&pq, succ)
+				default:
+					heap.Fix(&pq, succ.index)
+				}
+			}
+		}
+
+		if goal != nil {
+			best.Path = nil
+			for curr := goal; curr != nil; curr = curr.parent {
+				best.Path = append(best.Path, curr.state)
+			}
+			for i, j := 0, len(best.Path)-1; i < j; i, j = i+1, j-1 {
+				best.Path[i], best.Path[j] = best.Path[j], best.Path[i]
+			}
+			best.Cost, best.Weight = goal.cost, w
+
+			// The shortest path cost is at least the minimum unweighted
+			// Cost() + Estimate() on the frontier.
+			min := best.Cost
+			for _, s := range pq.items {
+				if f := s.cost + s.estimate/w; f < min {
+					min = f
+				}
+			}
+			best.Bound = w
+			if min > 0 && best.Cost/min < best.Bound {
+				best.Bound = best.Cost / min
+			}
+		} else if !math.IsInf(best.Cost, 1) {
+			// No cheaper path exists.
+			best.Bound = 1
+		}
+
+		if goal != nil || best.Bound == 1 {
+			best.Expanded, best.Elapsed = expanded, time.Since(begin)
+			if improved != nil {
+				improved(best)
+			}
+		}
+
+		switch {
+		case math.IsInf(best.Cost, 1):
+			return best, ErrNotFound
+		case best.Bound <= 1:
+			best.Bound = 1
+			return best, nil
+		}
+	}
+}
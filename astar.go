@@ -14,6 +14,12 @@
 // If Estimate() is optimal, the complexity is O(n).
 //
 // The algorithm is implemented as a Search() function which takes astar.Interface as a parameter.
+// SearchG() is its type-safe variant for astar.InterfaceG[S] with states of a concrete type S.
+// SearchProblem() takes astar.Problem[S], which has no current state to move and can be
+// shared between concurrent searches. IDAStar() is a memory-bounded alternative to Search().
+// States that can't be compared with ==, such as slices, need to be told apart by a Keyer
+// or the Key() option. The searches taking a context return panics in the problem's methods
+// as a *PanicError instead of crashing the program.
 //
 //
 // Basic usage (counting to 10):
@@ -57,8 +63,9 @@
 package astar
 
 import (
-	"container/heap"
+	"context"
 	"errors"
+	"time"
 )
 
 // ErrNotFound means that the final state cannot be reached from the given start state.
@@ -88,37 +95,80 @@ type Interface interface {
 	Estimate(interface{}) float64
 }
 
-type state struct {
-	state          interface{}
+// InterfaceG is a type-safe counterpart of Interface for states of type S.
+// Search methods receive and return S values directly, so implementations
+// don't need type assertions. Any Interface is an InterfaceG[interface{}].
+type InterfaceG[S comparable] interface {
+	// Initial state.
+	Start() S
+
+	// Is this state final?
+	Finish() bool
+
+	// Move to a new state.
+	Move(S)
+
+	// Available moves from the current state.
+	Successors() []S
+
+	// Path cost between the current and the given state.
+	Cost(S) float64
+
+	// Heuristic estimate of “how far to go?” between the given
+	// and the final state. Smaller values mean closer.
+	Estimate(S) float64
+}
+
+type state[S comparable] struct {
+	state          S
 	cost, estimate float64
 	index          int
+
+	// The state this one has been reached from
+	// (to reconstruct the shortest path at the end of the search).
+	parent *state[S]
+
+	// Is the state explored or is it still on the frontier?
+	explored bool
+
+	// Order in which states are put on the frontier (for tie-breaking).
+	seq int
+
+	// Bucket holding the state in BucketQueue and RadixHeap.
+	bucket int
 }
 
-type states []*state
+type states[S comparable] struct {
+	items []*state[S]
+
+	// Orders states with equal Cost() + Estimate() values,
+	// nil if the order doesn't matter.
+	tie func(a, b *state[S]) bool
+}
 
-func (pq states) Len() int           { return len(pq) }
-func (pq states) Empty() bool        { return len(pq) == 0 }
-func (pq states) Less(i, j int) bool { return pq[i].cost+pq[i].estimate < pq[j].cost+pq[j].estimate }
-func (pq states) Swap(i, j int) {
-	pq[i], pq[j] = pq[j], pq[i]
+func (pq *states[S]) Len() int           { return len(pq.items) }
+func (pq *states[S]) Empty() bool        { return len(pq.items) == 0 }
+func (pq *states[S]) Less(i, j int) bool { return before(pq.items[i], pq.items[j], pq.tie) }
+func (pq *states[S]) Swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
 
 	// Index is maintained for heap.Fix().
-	pq[i].index = i
-	pq[j].index = j
+	pq.items[i].index = i
+	pq.items[j].index = j
 }
 
-func (pq *states) Push(x interface{}) {
-	n := len(*pq)
-	item := x.(*state)
+func (pq *states[S]) Push(x interface{}) {
+	n := len(pq.items)
+	item := x.(*state[S])
 	item.index = n
-	*pq = append(*pq, item)
+	pq.items = append(pq.items, item)
 }
 
-func (pq *states) Pop() interface{} {
-	old := *pq
+func (pq *states[S]) Pop() interface{} {
+	old := pq.items
 	n := len(old)
 	x := old[n-1]
-	*pq = old[0 : n-1]
+	pq.items = old[0 : n-1]
 	return x
 }
 
@@ -127,87 +177,271 @@ func (pq *states) Pop() interface{} {
 // 1) the shortest path to the final state, and 2) a sequence of explored states.
 // If the shortest path cannot be found, ErrNotFound error is returned.
 func Search(p Interface) ([]interface{}, []interface{}, error) {
-	// Priority queue of states on the frontier.
-	// Initialized with the start state.
-	pq := states{{state: p.Start(), estimate: p.Estimate(p.Start())}}
-	heap.Init(&pq)
+	res, err := search(context.Background(), Adapt(p), newConfig(nil))
+	return res.Path, res.Steps, err
+}
+
+// SearchG is the same as Search, but works with states of type S and
+// returns the path and explored states as []S.
+func SearchG[S comparable](p InterfaceG[S]) ([]S, []S, error) {
+	res, err := search(context.Background(), AdaptG(p), newConfig(nil))
+	return res.Path, res.Steps, err
+}
+
+// SearchContext is the same as Search, but stops when ctx is done or when
+// one of the limits given in opts is hit. In the former case ctx.Err() is
+// returned, in the latter a *LimitError. Either way, the states explored
+// so far are returned as well. A panic in one of p's methods is returned
+// as a *PanicError (see RecoverPanics()).
+func SearchContext(ctx context.Context, p Interface, opts ...Option) ([]interface{}, []interface{}, error) {
+	res, err := search(ctx, Adapt(p), newContextConfig(opts))
+	return res.Path, res.Steps, err
+}
+
+// SearchContextG is the same as SearchContext for states of type S.
+func SearchContextG[S comparable](ctx context.Context, p InterfaceG[S], opts ...Option) ([]S, []S, error) {
+	res, err := search(ctx, AdaptG(p), newContextConfig(opts))
+	return res.Path, res.Steps, err
+}
 
-	// States currently on the frontier.
-	queuedLinks := map[interface{}]*state{}
+func search[S comparable](ctx context.Context, p Problem[S], c *config) (res *Result[S], err error) {
+	res = &Result[S]{}
+	begin := time.Now()
+	defer func() { res.Duration = time.Since(begin) }()
 
-	// States explored so far.
-	explored := map[interface{}]bool{}
+	observer, err := observerFor[S](c)
+	if err != nil {
+		return res, err
+	}
 
-	// State transitions from start to finish (to reconstruct
-	// the shortest path at the end of the search).
-	transitions := map[interface{}]interface{}{}
+	done := ctx.Done()
 
 	// Sequence of states in the order they have been explored.
-	steps := []interface{}{}
+	steps := newTrail[S](c.lastSteps)
+	defer func() { res.Steps = steps.slice() }()
 
-	p.Move(p.Start())
+	tie, err := tieBreakerFor[S](c)
+	if err != nil {
+		return res, err
+	}
+	key, err := keyFor[S](c, p)
+	if err != nil {
+		return res, err
+	}
+	useKey(p, key)
+
+	// Return panics in p's methods as errors.
+	if c.recoverPanics {
+		g := newGuard(p)
+		if key != nil {
+			key = g.keys(key)
+		}
+		p = g
+		defer g.catch(&err)
+	}
+
+	// Priority queue of states on the frontier.
+	// Initialized with the start state.
+	start := &state[S]{state: p.Start()}
+	start.estimate = p.Heuristic(start.state)
+	pq, err := newFrontier(c, tie)
+	if err != nil {
+		return res, err
+	}
+	if err := pq.insert(start); err != nil {
+		return res, err
+	}
+	pushed := 1
+	res.Generated, res.MaxFrontier = 1, 1
+
+	// States seen so far, both on the frontier and explored, by key.
+	seen := newStateMap[S, *state[S]](key, c.sizeHint)
+	seen.set(start.state, start)
+	explored := 0
 
 	// Exhaust all successor states.
-	for !pq.Empty() {
-		// Pick a state with a minimum Cost() + Estimate() value.
-		current := heap.Pop(&pq).(*state)
-		delete(queuedLinks, current.state)
-		explored[current.state] = true
+	for pq.Len() > 0 {
+		select {
+		case <-done:
+			return res, ctx.Err()
+		default:
+		}
 
-		// Move to the new state.
-		p.Move(current.state)
+		if c.maxExpanded > 0 && res.Expanded >= c.maxExpanded {
+			return res, &LimitError{"expanded", c.maxExpanded}
+		}
+		if c.maxExplored > 0 && explored >= c.maxExplored {
+			return res, &LimitError{"explored", c.maxExplored}
+		}
 
-		steps = append(steps, current.state)
+		// Pick a state with a minimum Cost() + Estimate() value.
+		current := pq.popMin()
+		current.explored = true
+		explored++
+
+		res.Expanded++
+		steps.add(current.state)
+		if observer.Expand != nil {
+			observer.Expand(event(current.state, current.parent, current.cost, current.estimate))
+		}
 
 		// If the state is final, terminate.
-		if p.Finish() {
+		if p.IsGoal(current.state) {
 			// Reconstruct the path from finish to start.
-			return func() []interface{} {
-				path := []interface{}{current.state}
-				for {
-					if _, ok := transitions[current.state]; !ok {
-						break
-					}
-					current.state = transitions[current.state]
+			for curr := current; curr != nil; curr = curr.parent {
+				res.Path = append(res.Path, curr.state)
+				res.Nodes = append(res.Nodes, Node[S]{
+					State: curr.state,
+					G:     curr.cost,
+					H:     curr.estimate,
+					F:     curr.cost + curr.estimate,
+				})
+			}
 
-					// Reverse.
-					path = append([]interface{}{current.state}, path...)
+			// Reverse.
+			for i, j := 0, len(res.Path)-1; i < j; i, j = i+1, j-1 {
+				res.Path[i], res.Path[j] = res.Path[j], res.Path[i]
+				res.Nodes[i], res.Nodes[j] = res.Nodes[j], res.Nodes[i]
+			}
 
-				}
-				return path
-			}(), steps, nil
+			res.Cost = current.cost
+			return res, nil
 		}
 
-		for _, succ := range p.Successors() {
-			// Don't re-explore.
-			if explored[succ] {
-				continue
-			}
+		for _, edge := range p.Neighbors(current.state) {
+			succ := edge.To
 
 			// Path cost so far.
-			cost := current.cost + p.Cost(succ)
+			cost := current.cost + edge.Cost
+
+			seenState, ok := seen.get(succ)
+
+			if c.checkConsistency {
+				h := p.Heuristic(succ)
+				if excess := current.estimate - (edge.Cost + h); excess > epsilon {
+					res.Violations = append(res.Violations, Violation[S]{Inconsistent, current.state, succ, current.estimate, excess})
+				}
+			}
+
+			if ok && seenState.explored {
+				// Reopen the explored state if the path is cheaper.
+				if c.reopen && cost < seenState.cost {
+					seenState.cost = cost
+					seenState.parent = current
+					seenState.explored = false
+					explored--
+					seenState.seq = pushed
+					pushed++
+					if err := pq.insert(seenState); err != nil {
+						return res, err
+					}
+					res.Reopened++
+					if observer.Generate != nil {
+						observer.Generate(Event[S]{succ, current.state, cost, seenState.estimate})
+					}
+
+					if pq.Len() > res.MaxFrontier {
+						res.MaxFrontier = pq.Len()
+					}
+					if c.maxFrontier > 0 && pq.Len() > c.maxFrontier {
+						return res, &LimitError{"frontier", c.maxFrontier}
+					}
+					continue
+				}
+
+				// Don't re-explore.
+				if observer.Skip != nil {
+					observer.Skip(Event[S]{succ, current.state, cost, seenState.estimate})
+				}
+				continue
+			}
 
 			// Add a successor to the frontier.
-			if queuedState, ok := queuedLinks[succ]; ok {
+			if ok {
 				// If the successor is already on the frontier,
 				// update its path cost.
-				if cost < queuedState.cost {
-					queuedState.cost = cost
-					heap.Fix(&pq, queuedState.index)
-					transitions[succ] = current.state
+				if cost < seenState.cost {
+					seenState.cost = cost
+					seenState.parent = current
+					if err := pq.decrease(seenState); err != nil {
+						return res, err
+					}
+					res.Updated++
+					if observer.Improve != nil {
+						observer.Improve(Event[S]{succ, current.state, cost, seenState.estimate})
+					}
 				}
 			} else {
-				state := state{
+				state := &state[S]{
 					state:    succ,
 					cost:     cost,
-					estimate: p.Estimate(succ),
+					estimate: p.Heuristic(succ),
+					parent:   current,
+					seq:      pushed,
+				}
+				pushed++
+				if err := pq.insert(state); err != nil {
+					return res, err
+				}
+				seen.set(succ, state)
+				res.Generated++
+				if observer.Generate != nil {
+					observer.Generate(Event[S]{succ, current.state, cost, state.estimate})
+				}
+
+				if pq.Len() > res.MaxFrontier {
+					res.MaxFrontier = pq.Len()
+				}
+				if c.maxFrontier > 0 && pq.Len() > c.maxFrontier {
+					return res, &LimitError{"frontier", c.maxFrontier}
 				}
-				heap.Push(&pq, &state)
-				queuedLinks[succ] = &state
-				transitions[succ] = current.state
 			}
 		}
 	}
 
-	return nil, steps, ErrNotFound
+	return res, ErrNotFound
+}
+
+// event makes an Event for a state reached from the parent state record
+// (nil for the start state).
+func event[S comparable](s S, parent *state[S], g, h float64) Event[S] {
+	e := Event[S]{State: s, G: g, H: h}
+	if parent != nil {
+		e.Parent = parent.state
+	}
+	return e
+}
+
+// trail records explored states: all of them if limit is negative,
+// or only the last limit ones.
+type trail[S comparable] struct {
+	limit int
+	steps []S
+
+	// Total number of states added.
+	n int
+}
+
+func newTrail[S comparable](limit int) *trail[S] {
+	return &trail[S]{limit: limit, steps: []S{}}
+}
+
+func (t *trail[S]) add(s S) {
+	switch {
+	case t.limit < 0 || len(t.steps) < t.limit:
+		t.steps = append(t.steps, s)
+	case t.limit > 0:
+		// Overwrite the oldest state.
+		t.steps[t.n%t.limit] = s
+	}
+	t.n++
+}
+
+// slice returns the recorded states from the oldest to the latest.
+func (t *trail[S]) slice() []S {
+	if t.limit <= 0 || t.n <= t.limit {
+		return t.steps
+	}
+	i := t.n % t.limit
+	return append(t.steps[i:len(t.steps):len(t.steps)], t.steps[:i]...)
 }
@@ -1,7 +1,14 @@
 package astar_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -310,3 +317,948 @@ func TestEstimate(t *testing.T) {
 		}
 	}
 }
+
+// typedGraph is a graph with string states for SearchG.
+type typedGraph struct {
+	*graph
+}
+
+func (g typedGraph) Start() string     { return Start }
+func (g typedGraph) Move(state string) { g.curr = state }
+func (g typedGraph) Successors() []string {
+	successors := []string{}
+	for succ := range g.edges[g.curr] {
+		successors = append(successors, succ)
+	}
+
+	return successors
+}
+func (g typedGraph) Cost(given string) float64     { return g.edges[g.curr][given] }
+func (g typedGraph) Estimate(given string) float64 { return estimateFunc(given) }
+
+func TestSearchG(t *testing.T) {
+	estimateFunc = func(given interface{}) float64 { return 1 }
+
+	for _, test := range BasicTests {
+		Start, Finish = test.out[:1], test.out[len(test.out)-1:]
+
+		path, _, err := SearchG[string](typedGraph{test.g})
+		if err != nil {
+			t.Errorf("%q: failed with error %v", test.name, err)
+			continue
+		}
+		if actual := strings.Join(path, ""); actual != test.out {
+			t.Errorf("%q: got %v, want %v", test.name, actual, test.out)
+		}
+	}
+
+	Start, Finish = "A", "B"
+	if path, _, err := SearchG[string](typedGraph{&graph{edges: map[string]map[string]float64{
+		"A": {"A": 1},
+	}}}); err != ErrNotFound {
+		t.Errorf("unreachable finish: got %v, %v, want ErrNotFound", path, err)
+	}
+}
+
+// odd counts from 1 by twos, so it never gets to an even finish.
+type odd int
+
+func (n odd) Start() interface{}             { return odd(1) }
+func (n odd) Finish() bool                   { return n == 10 }
+func (n *odd) Move(x interface{})            { *n = x.(odd) }
+func (n odd) Successors() []interface{}      { return []interface{}{n + 2} }
+func (n odd) Cost(x interface{}) float64     { return 1 }
+func (n odd) Estimate(x interface{}) float64 { return 1 }
+
+func TestSearchContext(t *testing.T) {
+	var n odd
+
+	if _, steps, err := SearchContext(context.Background(), &n, MaxExpanded(5)); !errors.Is(err, ErrLimitExceeded) || len(steps) != 5 {
+		t.Errorf("expanded limit: got %v, %d steps, want ErrLimitExceeded, 5 steps", err, len(steps))
+	}
+
+	if _, _, err := SearchContext(context.Background(), &n, MaxExplored(3)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("explored limit: got %v, want ErrLimitExceeded", err)
+	}
+
+	Start, Finish = "A", "Z"
+	g := &graph{edges: map[string]map[string]float64{
+		"A": {"B": 1, "C": 1, "D": 1},
+	}}
+	var limitErr *LimitError
+	if _, _, err := SearchContext(context.Background(), g, MaxFrontier(2)); !errors.As(err, &limitErr) || limitErr.Limit != "frontier" {
+		t.Errorf("frontier limit: got %v, want frontier *LimitError", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := SearchContext(ctx, &n); err != context.Canceled {
+		t.Errorf("canceled: got %v, want %v", err, context.Canceled)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := SearchContext(ctx, &n); err != context.DeadlineExceeded {
+		t.Errorf("deadline: got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// statelessGraph is a Problem over the same edges as graph.
+type statelessGraph struct {
+	edges         map[string]map[string]float64
+	start, finish string
+}
+
+func (g statelessGraph) Start() string                  { return g.start }
+func (g statelessGraph) IsGoal(state string) bool       { return state == g.finish }
+func (g statelessGraph) Heuristic(state string) float64 { return 0 }
+func (g statelessGraph) Neighbors(state string) []Edge[string] {
+	edges := []Edge[string]{}
+	for to, cost := range g.edges[state] {
+		edges = append(edges, Edge[string]{to, cost})
+	}
+	return edges
+}
+
+func TestSearchProblem(t *testing.T) {
+	var wg sync.WaitGroup
+	for _, test := range BasicTests {
+		g := statelessGraph{test.g.edges, test.out[:1], test.out[len(test.out)-1:]}
+
+		// Run several queries over the same Problem at once.
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(name, out string) {
+				defer wg.Done()
+				if path, _, err := SearchProblem[string](context.Background(), g); strings.Join(path, "") != out {
+					t.Errorf("%q: got %v, want %v, error %v", name, path, out, err)
+				}
+			}(test.name, test.out)
+		}
+	}
+	wg.Wait()
+}
+
+func TestAdapt(t *testing.T) {
+	estimateFunc = func(given interface{}) float64 { return 1 }
+
+	for _, test := range BasicTests {
+		Start, Finish = test.out[:1], test.out[len(test.out)-1:]
+
+		if path, _, err := SearchProblem(context.Background(), Adapt(test.g)); stringize(path) != test.out {
+			t.Errorf("%q: got %v, want %v, error %v", test.name, stringize(path), test.out, err)
+		}
+	}
+}
+
+func TestSearchResult(t *testing.T) {
+	test := BasicTests[len(BasicTests)-1] // zigzag
+	g := statelessGraph{test.g.edges, "A", "D"}
+
+	res, err := SearchResult[string](context.Background(), g)
+	if err != nil {
+		t.Fatalf("failed with error %v", err)
+	}
+	if actual := strings.Join(res.Path, ""); actual != test.out {
+		t.Errorf("path: got %v, want %v", actual, test.out)
+	}
+	if res.Cost != 7 {
+		t.Errorf("cost: got %v, want 7", res.Cost)
+	}
+	if len(res.Nodes) != len(res.Path) {
+		t.Fatalf("nodes: got %d, want %d", len(res.Nodes), len(res.Path))
+	}
+	for i, node := range res.Nodes {
+		if node.State != res.Path[i] || node.G != float64(i) || node.F != node.G+node.H {
+			t.Errorf("node #%d: got %+v", i, node)
+		}
+	}
+	if res.Expanded != len(res.Steps) || res.Generated < res.Expanded || res.MaxFrontier < 1 {
+		t.Errorf("statistics: got %+v", res)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	// (A)--1--(B)
+	//  |     /
+	//  3   1
+	//  | /
+	// (D)--1--(E)
+	g := statelessGraph{map[string]map[string]float64{
+		"A": {"B": 1, "D": 3},
+		"B": {"A": 1, "D": 1},
+		"D": {"A": 3, "B": 1, "E": 1},
+		"E": {"D": 1},
+	}, "A", "E"}
+
+	var expanded, generated, improved, skipped []string
+	record := func(events *[]string) func(Event[string]) {
+		return func(e Event[string]) {
+			*events = append(*events, fmt.Sprintf("%s%s%v", e.Parent, e.State, e.G))
+		}
+	}
+
+	if _, _, err := SearchProblem[string](context.Background(), g, Observe(Observer[string]{
+		Expand:   record(&expanded),
+		Generate: record(&generated),
+		Improve:  record(&improved),
+		Skip:     record(&skipped),
+	})); err != nil {
+		t.Fatalf("failed with error %v", err)
+	}
+
+	if actual := strings.Join(expanded, " "); actual != "A0 AB1 BD2 DE3" {
+		t.Errorf("expanded: got %v", actual)
+	}
+	if len(generated) != 3 {
+		t.Errorf("generated: got %v", generated)
+	}
+	if actual := strings.Join(improved, " "); actual != "BD2" {
+		t.Errorf("improved: got %v", improved)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("skipped: got %v", skipped)
+	}
+
+	if _, _, err := SearchProblem[string](context.Background(), g, Observe(Observer[int]{})); err == nil {
+		t.Errorf("mismatched observer: got no error")
+	}
+}
+
+func TestLastSteps(t *testing.T) {
+	var n odd
+
+	for _, test := range []struct {
+		last int
+		out  string
+	}{
+		{-1, "[1 3 5 7 9 11 13 15 17 19]"},
+		{0, "[]"},
+		{3, "[15 17 19]"},
+		{4, "[13 15 17 19]"},
+		{10, "[1 3 5 7 9 11 13 15 17 19]"},
+		{20, "[1 3 5 7 9 11 13 15 17 19]"},
+	} {
+		_, steps, _ := SearchContext(context.Background(), &n, MaxExpanded(10), LastSteps(test.last))
+		if actual := fmt.Sprint(steps); actual != test.out {
+			t.Errorf("last %d: got %v, want %v", test.last, actual, test.out)
+		}
+	}
+}
+
+// inconsistentGraph has an admissible, but inconsistent estimate.
+type inconsistentGraph struct {
+	statelessGraph
+}
+
+func (g inconsistentGraph) Heuristic(state string) float64 {
+	return map[string]float64{"A": 3}[state]
+}
+
+func TestReopen(t *testing.T) {
+	//     (A)
+	//    /   \
+	//   1     1
+	//  /       \
+	// (S)--3--(B)--3--(G)
+	g := inconsistentGraph{statelessGraph{map[string]map[string]float64{
+		"S": {"A": 1, "B": 3},
+		"A": {"B": 1},
+		"B": {"G": 3},
+	}, "S", "G"}}
+
+	if res, _ := SearchResult[string](context.Background(), g); res.Cost != 6 || res.Reopened != 0 {
+		t.Errorf("no reopening: got cost %v, %d reopened, want 6, 0", res.Cost, res.Reopened)
+	}
+
+	res, err := SearchResult[string](context.Background(), g, Reopen())
+	if err != nil {
+		t.Fatalf("failed with error %v", err)
+	}
+	if actual := strings.Join(res.Path, ""); actual != "SABG" || res.Cost != 5 || res.Reopened != 1 {
+		t.Errorf("reopening: got %v, cost %v, %d reopened, want SABG, 5, 1", actual, res.Cost, res.Reopened)
+	}
+}
+
+// overestimatingGraph has an inadmissible estimate.
+type overestimatingGraph struct {
+	statelessGraph
+}
+
+func (g overestimatingGraph) Heuristic(state string) float64 {
+	return map[string]float64{"S": 10, "A": 3}[state]
+}
+
+// pessimisticGraph overestimates everywhere but at the finish.
+type pessimisticGraph struct {
+	statelessGraph
+}
+
+func (g pessimisticGraph) Heuristic(state string) float64 {
+	if state == g.finish {
+		return 0
+	}
+	return 100
+}
+
+func TestVerify(t *testing.T) {
+	edges := map[string]map[string]float64{
+		"S": {"A": 1, "B": 3},
+		"A": {"B": 1},
+		"B": {"G": 3},
+	}
+
+	violations, err := Verify[string](inconsistentGraph{statelessGraph{edges, "S", "G"}}, 0)
+	if err != nil {
+		t.Fatalf("failed with error %v", err)
+	}
+	if actual := fmt.Sprint(violations); actual != "[inconsistent: h(A) = 3 exceeds c + h(B) by 2]" {
+		t.Errorf("inconsistent: got %v", actual)
+	}
+
+	violations, _ = Verify[string](overestimatingGraph{statelessGraph{edges, "S", "G"}}, 0)
+	var inadmissible []Violation[string]
+	for _, v := range violations {
+		if v.Kind == Inadmissible {
+			inadmissible = append(inadmissible, v)
+		}
+	}
+	if actual := fmt.Sprint(inadmissible); actual != "[inadmissible: h(S) = 10 exceeds the actual cost by 5]" {
+		t.Errorf("inadmissible: got %v", actual)
+	}
+
+	// Violations are reported in the order the states are reached.
+	chain := map[string]map[string]float64{"S": {"A": 1}, "A": {"B": 1}, "B": {"C": 1}, "C": {"G": 1}}
+	for i := 0; i < 10; i++ {
+		violations, _ = Verify[string](pessimisticGraph{statelessGraph{chain, "S", "G"}}, 0)
+		states := []string{}
+		for _, v := range violations {
+			if v.Kind == Inadmissible {
+				states = append(states, v.State)
+			}
+		}
+		if actual := strings.Join(states, ""); actual != "SABC" {
+			t.Fatalf("inadmissible order: got %v, want SABC", actual)
+		}
+	}
+
+	if _, err := Verify[string](inconsistentGraph{statelessGraph{edges, "S", "G"}}, 2); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("limit: got %v, want ErrLimitExceeded", err)
+	}
+}
+
+func TestCheckConsistency(t *testing.T) {
+	g := inconsistentGraph{statelessGraph{map[string]map[string]float64{
+		"S": {"A": 1, "B": 3},
+		"A": {"B": 1},
+		"B": {"G": 3},
+	}, "S", "G"}}
+
+	res, _ := SearchResult[string](context.Background(), g, CheckConsistency())
+	if len(res.Violations) != 1 || res.Violations[0].State != "A" || res.Violations[0].Excess != 2 {
+		t.Errorf("got %v", res.Violations)
+	}
+}
+
+// openGrid is an n×n grid without walls, from the top left
+// to the bottom right corner.
+type openGrid int
+
+func (g openGrid) Start() [2]int            { return [2]int{0, 0} }
+func (g openGrid) IsGoal(state [2]int) bool { return state == [2]int{int(g) - 1, int(g) - 1} }
+func (g openGrid) Heuristic(state [2]int) float64 {
+	return float64(2*(int(g)-1) - state[0] - state[1])
+}
+func (g openGrid) Neighbors(state [2]int) []Edge[[2]int] {
+	edges := []Edge[[2]int]{}
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		i, j := state[0]+d[0], state[1]+d[1]
+		if i >= 0 && j >= 0 && i < int(g) && j < int(g) {
+			edges = append(edges, Edge[[2]int]{[2]int{i, j}, 1})
+		}
+	}
+	return edges
+}
+
+func TestTieBreaking(t *testing.T) {
+	g := openGrid(10)
+
+	expanded := map[TieBreak]int{}
+	for _, tie := range []TieBreak{AnyTie, HigherG, LowerH, LIFO, FIFO} {
+		res, err := SearchResult[[2]int](context.Background(), g, TieBreaking(tie))
+		if err != nil || res.Cost != 18 {
+			t.Fatalf("policy %d: got cost %v, error %v, want 18", tie, res.Cost, err)
+		}
+		expanded[tie] = res.Expanded
+	}
+	if expanded[HigherG] != 19 || expanded[LowerH] != 19 {
+		t.Errorf("HigherG, LowerH: got %d, %d expanded, want 19", expanded[HigherG], expanded[LowerH])
+	}
+	if expanded[FIFO] <= expanded[LIFO] {
+		t.Errorf("FIFO: got %d expanded, want more than LIFO %d", expanded[FIFO], expanded[LIFO])
+	}
+
+	// Prefer going right, then down.
+	res, _ := SearchResult[[2]int](context.Background(), g, TieBreakFunc(func(a, b Node[[2]int]) bool {
+		if a.G != b.G {
+			return a.G > b.G
+		}
+		return a.State[1] > b.State[1]
+	}))
+	if actual := fmt.Sprint(res.Path[:3]); res.Expanded != 19 || actual != "[[0 0] [0 1] [0 2]]" {
+		t.Errorf("custom: got %d expanded, path %v", res.Expanded, actual)
+	}
+
+	if _, err := SearchResult[[2]int](context.Background(), g, TieBreakFunc(func(a, b Node[string]) bool { return true })); err == nil {
+		t.Errorf("mismatched function: got no error")
+	}
+}
+
+// number counts to 10 by ones.
+type number int
+
+func (n number) Start() interface{}         { return number(1) }
+func (n number) Finish() bool               { return n == 10 }
+func (n *number) Move(x interface{})        { *n = x.(number) }
+func (n number) Successors() []interface{}  { return []interface{}{n - 1, n + 1} }
+func (n number) Cost(x interface{}) float64 { return 1 }
+func (n number) Estimate(x interface{}) float64 {
+	return math.Abs(10 - float64(x.(number)))
+}
+
+func TestIDAStar(t *testing.T) {
+	var n number
+	if path, steps, err := IDAStar(&n); fmt.Sprint(path) != "[1 2 3 4 5 6 7 8 9 10]" || steps == nil || len(steps) != 0 || err != nil {
+		t.Errorf("counting: got %v, %v, %v", path, steps, err)
+	}
+
+	for _, test := range BasicTests {
+		g := statelessGraph{test.g.edges, test.out[:1], test.out[len(test.out)-1:]}
+
+		want, _ := SearchResult[string](context.Background(), g)
+		if res, err := IDAStarResult[string](context.Background(), g); err != nil || res.Cost != want.Cost {
+			t.Errorf("%q: got cost %v, error %v, want %v", test.name, res.Cost, err, want.Cost)
+		}
+	}
+
+	Start, Finish = "A", "B"
+	if path, _, err := IDAStar(&graph{edges: map[string]map[string]float64{
+		"A": {"A": 1},
+	}}); err != ErrNotFound {
+		t.Errorf("unreachable finish: got %v, %v, want ErrNotFound", path, err)
+	}
+
+	// IDAStarContext records the explored states of all the iterations.
+	if _, steps, err := IDAStarContext(context.Background(), &n); len(steps) != 10 || err != nil {
+		t.Errorf("counting steps: got %v, %v", steps, err)
+	}
+
+	var o odd
+	if _, steps, err := IDAStarContext(context.Background(), &o, MaxExpanded(5)); !errors.Is(err, ErrLimitExceeded) || len(steps) != 5 {
+		t.Errorf("expanded limit: got %v, %d steps, want ErrLimitExceeded, 5 steps", err, len(steps))
+	}
+}
+
+// reversibleGrid is a wallGrid searchable backwards.
+type reversibleGrid struct {
+	wallGrid
+}
+
+func (g reversibleGrid) Goal() [2]int { return [2]int{g.n - 1, g.n - 1} }
+func (g reversibleGrid) Predecessors(state [2]int) []Edge[[2]int] {
+	return g.Neighbors(state)
+}
+func (g reversibleGrid) HeuristicFromStart(state [2]int) float64 {
+	return float64(state[0] + state[1])
+}
+
+// reversibleGraph is an undirected statelessGraph searchable backwards.
+type reversibleGraph struct {
+	statelessGraph
+}
+
+func (g reversibleGraph) Goal() string                             { return g.finish }
+func (g reversibleGraph) Predecessors(state string) []Edge[string] { return g.Neighbors(state) }
+func (g reversibleGraph) HeuristicFromStart(state string) float64  { return 0 }
+
+func TestSearchBidirectional(t *testing.T) {
+	for _, test := range BasicTests {
+		g := reversibleGraph{statelessGraph{test.g.edges, test.out[:1], test.out[len(test.out)-1:]}}
+
+		want, _ := SearchResult[string](context.Background(), g)
+		if res, err := SearchBidirectional[string](context.Background(), g); err != nil || res.Cost != want.Cost || strings.Join(res.Path, "") != test.out {
+			t.Errorf("%q: got %v, cost %v, error %v, want %v, %v", test.name, res.Path, res.Cost, err, test.out, want.Cost)
+		}
+	}
+
+	for seed := int64(0); seed < 50; seed++ {
+		g := reversibleGrid{newWallGrid(30, 0.3, seed)}
+
+		want, wantErr := SearchResult[[2]int](context.Background(), g)
+		res, err := SearchBidirectional[[2]int](context.Background(), g)
+		if err != wantErr || res.Cost != want.Cost {
+			t.Errorf("maze #%d: got cost %v, error %v, want %v, %v", seed, res.Cost, err, want.Cost, wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if len(res.Path) != int(res.Cost)+1 || res.Path[0] != g.Start() || res.Path[len(res.Path)-1] != g.Goal() {
+			t.Errorf("maze #%d: got path %v", seed, res.Path)
+		}
+		for i, node := range res.Nodes {
+			if node.G != float64(i) {
+				t.Errorf("maze #%d: got node #%d %+v", seed, i, node)
+			}
+		}
+	}
+
+	g := reversibleGraph{statelessGraph{map[string]map[string]float64{}, "A", "A"}}
+	if res, err := SearchBidirectional[string](context.Background(), g); err != nil || fmt.Sprint(res.Path) != "[A]" {
+		t.Errorf("trivial: got %v, %v", res.Path, err)
+	}
+}
+
+func TestSearchAnytime(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		g := newWallGrid(40, 0.25, seed)
+
+		want, wantErr := SearchResult[[2]int](context.Background(), g)
+
+		var solutions []Solution[[2]int]
+		best, err := SearchAnytime[[2]int](context.Background(), g, 5, 1, func(s Solution[[2]int]) {
+			solutions = append(solutions, s)
+		})
+		if err != wantErr || (err == nil && best.Cost != want.Cost) {
+			t.Errorf("maze #%d: got cost %v, error %v, want %v, %v", seed, best.Cost, err, want.Cost, wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if len(solutions) == 0 || solutions[len(solutions)-1].Bound != 1 {
+			t.Fatalf("maze #%d: got solutions %+v, want the last one proven optimal", seed, solutions)
+		}
+		for i, s := range solutions {
+			if s.Cost > s.Bound*want.Cost+1e-9 {
+				t.Errorf("maze #%d, solution #%d: cost %v exceeds bound %v × %v", seed, i, s.Cost, s.Bound, want.Cost)
+			}
+			if i > 0 && s.Cost > solutions[i-1].Cost {
+				t.Errorf("maze #%d, solution #%d: cost %v, previous %v", seed, i, s.Cost, solutions[i-1].Cost)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := SearchAnytime[[2]int](ctx, openGrid(10), 2, 0.5, nil); err != context.Canceled {
+		t.Errorf("canceled: got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestKShortest(t *testing.T) {
+	// An example from the Wikipedia article on Yen's algorithm:
+	// a directed graph with 7 loopless paths from C to H.
+	edges := map[string]map[string]float64{
+		"C": {"D": 3, "E": 2},
+		"D": {"F": 4},
+		"E": {"D": 1, "F": 2, "G": 3},
+		"F": {"G": 2, "H": 1},
+		"G": {"H": 2},
+	}
+
+	results, err := KShortestPaths[string](context.Background(), statelessGraph{edges, "C", "H"}, 10)
+	if err != nil {
+		t.Fatalf("failed with error %v", err)
+	}
+	costs := []float64{}
+	for _, res := range results {
+		costs = append(costs, res.Cost)
+		if res.Nodes[len(res.Nodes)-1].G != res.Cost {
+			t.Errorf("%v: got nodes %+v, cost %v", res.Path, res.Nodes, res.Cost)
+		}
+	}
+	if actual := fmt.Sprint(costs); actual != "[5 7 8 8 8 11 11]" {
+		t.Errorf("costs: got %v, want [5 7 8 8 8 11 11]", actual)
+	}
+
+	Start, Finish = "C", "H"
+	estimateFunc = func(given interface{}) float64 { return 0 }
+	paths, err := KShortest(&graph{edges: edges}, 2)
+	if actual := fmt.Sprint(paths); err != nil || actual != "[[C E F H] [C E G H]]" {
+		t.Errorf("got %v, error %v, want [[C E F H] [C E G H]]", actual, err)
+	}
+
+	Start, Finish = "C", "Z"
+	if paths, err := KShortest(&graph{edges: edges}, 2); err != ErrNotFound {
+		t.Errorf("unreachable finish: got %v, %v, want ErrNotFound", paths, err)
+	}
+}
+
+// dynamicGrid is a wallGrid with a Distance for a Planner.
+type dynamicGrid struct {
+	wallGrid
+}
+
+func (g dynamicGrid) Predecessors(state [2]int) []Edge[[2]int] { return g.Neighbors(state) }
+func (g dynamicGrid) Distance(from, to [2]int) float64 {
+	return math.Abs(float64(from[0]-to[0])) + math.Abs(float64(from[1]-to[1]))
+}
+
+// movedGrid is a wallGrid starting at a given state.
+type movedGrid struct {
+	wallGrid
+	start [2]int
+}
+
+func (g movedGrid) Start() [2]int { return g.start }
+
+func TestPlanner(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for seed := int64(0); seed < 10; seed++ {
+		g := dynamicGrid{newWallGrid(20, 0.2, seed)}
+		planner := NewPlanner[[2]int](g, g.Start(), [2]int{g.n - 1, g.n - 1})
+		start := g.Start()
+
+		for round := 0; round < 30; round++ {
+			want, wantErr := SearchResult[[2]int](context.Background(), movedGrid{g.wallGrid, start})
+			res, err := planner.Plan(context.Background())
+			if err != wantErr || (err == nil && (res.Cost != want.Cost || len(res.Path) != len(want.Path))) {
+				t.Fatalf("maze #%d, round %d: got cost %v, error %v, want %v, %v", seed, round, res.Cost, err, want.Cost, wantErr)
+			}
+
+			// Move the agent a step along the path.
+			if err == nil && len(res.Path) > 2 {
+				start = res.Path[1]
+				planner.Move(start)
+			}
+
+			// Toggle a wall, except for the agent and goal cells.
+			i, j := r.Intn(g.n), r.Intn(g.n)
+			if [2]int{i, j} == start || i == g.n-1 && j == g.n-1 {
+				continue
+			}
+			changed := [][2]int{{i, j}}
+			for _, edge := range g.Neighbors([2]int{i, j}) {
+				changed = append(changed, edge.To)
+			}
+			g.walls[i*g.n+j] = !g.walls[i*g.n+j]
+			for _, edge := range g.Neighbors([2]int{i, j}) {
+				changed = append(changed, edge.To)
+			}
+			planner.UpdateEdges(changed...)
+		}
+	}
+}
+
+// pair counts up to (3, 2) in a slice, which can't be a map key.
+type pair struct {
+	curr []int
+}
+
+func (p pair) Start() interface{}         { return []int{0, 0} }
+func (p pair) Finish() bool               { return p.curr[0] == 3 && p.curr[1] == 2 }
+func (p *pair) Move(x interface{})        { p.curr = x.([]int) }
+func (p pair) Cost(x interface{}) float64 { return 1 }
+func (p pair) Successors() []interface{} {
+	succ := []interface{}{}
+	if p.curr[0] < 3 {
+		succ = append(succ, []int{p.curr[0] + 1, p.curr[1]})
+	}
+	if p.curr[1] < 2 {
+		succ = append(succ, []int{p.curr[0], p.curr[1] + 1})
+	}
+	return succ
+}
+func (p pair) Estimate(x interface{}) float64 {
+	s := x.([]int)
+	return math.Abs(3-float64(s[0])) + math.Abs(2-float64(s[1]))
+}
+
+// keyedPair tells pairs apart by Key.
+type keyedPair struct{ pair }
+
+func (p keyedPair) Key(x interface{}) interface{} { return fmt.Sprint(x) }
+
+// countedPair counts the moves.
+type countedPair struct {
+	keyedPair
+	moves int
+}
+
+func (p *countedPair) Move(x interface{}) {
+	p.moves++
+	p.keyedPair.Move(x)
+}
+
+func TestKey(t *testing.T) {
+	if path, _, err := Search(&keyedPair{}); err != nil || fmt.Sprint(path[0], path[len(path)-1]) != "[0 0] [3 2]" || len(path) != 6 {
+		t.Errorf("Keyer: got %v, %v", path, err)
+	}
+
+	// Moves are only made to states with a different key.
+	counted := &countedPair{}
+	if res, err := SearchResult[interface{}](context.Background(), Adapt(counted)); err != nil || counted.moves != res.Expanded {
+		t.Errorf("Keyer moves: got %d for %d expanded states, error %v", counted.moves, res.Expanded, err)
+	}
+
+	key := Key(func(x interface{}) interface{} { return fmt.Sprint(x) })
+	if path, _, err := SearchContext(context.Background(), &pair{}, key); err != nil || len(path) != 6 {
+		t.Errorf("Key: got %v, %v", path, err)
+	}
+	if res, err := IDAStarResult[interface{}](context.Background(), Adapt(&pair{}), key); err != nil || res.Cost != 5 {
+		t.Errorf("IDA* with Key: got %v, %v", res, err)
+	}
+	if violations, err := Verify[interface{}](Adapt(&keyedPair{}), 12); err != nil || len(violations) != 0 {
+		t.Errorf("Verify with Keyer: got %v, %v", violations, err)
+	}
+
+	// Keys can merge states, such as ones with the counts swapped.
+	symmetric := 0
+	sorted := Key(func(x interface{}) interface{} {
+		s := x.([]int)
+		if s[0] > s[1] {
+			return [2]int{s[1], s[0]}
+		}
+		return [2]int{s[0], s[1]}
+	})
+	obs := Observe(Observer[interface{}]{Expand: func(e Event[interface{}]) {
+		if s := fmt.Sprint(e.State); s == "[0 1]" || s == "[1 0]" {
+			symmetric++
+		}
+	}})
+	if _, _, err := SearchContext(context.Background(), &pair{}, sorted, obs); err != nil {
+		t.Errorf("symmetric: got %v", err)
+	}
+	if symmetric != 1 {
+		t.Errorf("symmetric: expanded [0 1] and [1 0] %d times, want once", symmetric)
+	}
+
+	if _, _, err := SearchContext(context.Background(), &pair{}, Key(func(s string) interface{} { return s })); err == nil {
+		t.Errorf("mismatched key: got no error")
+	}
+
+	// Keys of another type than the states.
+	test := BasicTests[0]
+	g := statelessGraph{test.g.edges, test.out[:1], test.out[len(test.out)-1:]}
+	first := Key(func(s string) interface{} { return s[0] })
+	if res, err := SearchResult[string](context.Background(), g, first); err != nil || strings.Join(res.Path, "") != test.out {
+		t.Errorf("typed Key: got %v, %v, want %v", res.Path, err, test.out)
+	}
+
+	// Yen's algorithm compares paths and excludes states by key.
+	paths, err := KShortest(&keyedPair{}, 3)
+	if err != nil || len(paths) != 3 || fmt.Sprint(paths[0]) == fmt.Sprint(paths[1]) || fmt.Sprint(paths[1]) == fmt.Sprint(paths[2]) {
+		t.Errorf("KShortest with Keyer: got %v, %v", paths, err)
+	}
+	if results, err := KShortestPaths[interface{}](context.Background(), Adapt(&pair{}), 3, key); err != nil || len(results) != 3 {
+		t.Errorf("KShortestPaths with Key: got %d paths, %v", len(results), err)
+	}
+
+	// The other algorithms don't support keys.
+	if _, err := SearchBidirectional[string](context.Background(), reversibleGraph{g}, first); err != ErrKeysUnsupported {
+		t.Errorf("SearchBidirectional with Key: got %v, want ErrKeysUnsupported", err)
+	}
+	if _, err := SearchAnytime[interface{}](context.Background(), Adapt(&keyedPair{}), 2, 1, nil); err != ErrKeysUnsupported {
+		t.Errorf("SearchAnytime with Keyer: got %v, want ErrKeysUnsupported", err)
+	}
+	grid := keyedGrid{dynamicGrid{newWallGrid(5, 0, 1)}}
+	if _, err := NewPlanner[[2]int](grid, grid.Start(), [2]int{4, 4}).Plan(context.Background()); err != ErrKeysUnsupported {
+		t.Errorf("Planner with Keyer: got %v, want ErrKeysUnsupported", err)
+	}
+}
+
+// keyedGrid is a dynamicGrid with a Keyer.
+type keyedGrid struct{ dynamicGrid }
+
+func (g keyedGrid) Key(state [2]int) interface{} { return state }
+
+// faultyNumber is a number panicking in its fault method at 3.
+type faultyNumber struct {
+	number
+	fault string
+}
+
+func (n *faultyNumber) check(callback string, x interface{}) {
+	if callback == n.fault && x == number(3) {
+		panic(callback + " failed")
+	}
+}
+
+func (n *faultyNumber) Start() interface{} {
+	n.check("Start", number(3))
+	return n.number.Start()
+}
+func (n *faultyNumber) Finish() bool {
+	n.check("Finish", n.number)
+	return n.number.Finish()
+}
+func (n *faultyNumber) Move(x interface{}) {
+	n.check("Move", x)
+	n.number.Move(x)
+}
+func (n *faultyNumber) Successors() []interface{} {
+	n.check("Successors", n.number)
+	if n.fault == "uncomparable" && n.number == 3 {
+		return []interface{}{[]int{4}}
+	}
+	return n.number.Successors()
+}
+func (n *faultyNumber) Cost(x interface{}) float64 {
+	if n.fault == "Cost" && x == number(3) {
+		return float64(len(x.(string)))
+	}
+	return n.number.Cost(x)
+}
+func (n *faultyNumber) Estimate(x interface{}) float64 {
+	n.check("Estimate", x)
+	return n.number.Estimate(x)
+}
+
+// faultyGrid is an openGrid panicking in Heuristic at {0, 1}.
+type faultyGrid struct{ openGrid }
+
+func (g faultyGrid) Heuristic(state [2]int) float64 {
+	if state == [2]int{0, 1} {
+		panic("Heuristic failed")
+	}
+	return g.openGrid.Heuristic(state)
+}
+
+// faultyReversible is a reversibleGraph panicking in Predecessors.
+type faultyReversible struct{ reversibleGraph }
+
+func (g faultyReversible) Predecessors(state string) []Edge[string] {
+	panic("Predecessors failed")
+}
+
+// faultyDynamic is a dynamicGrid panicking in Predecessors.
+type faultyDynamic struct{ dynamicGrid }
+
+func (g faultyDynamic) Predecessors(state [2]int) []Edge[[2]int] {
+	panic("Predecessors failed")
+}
+
+// panics tells whether f panics.
+func panics(f func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	f()
+	return false
+}
+
+func TestRecoverPanics(t *testing.T) {
+	for _, callback := range []string{"Start", "Move", "Successors", "Cost", "Estimate", "Finish"} {
+		for name, search := range map[string]func(Interface) error{
+			"SearchContext": func(p Interface) error {
+				_, _, err := SearchContext(context.Background(), p)
+				return err
+			},
+			"IDAStarContext": func(p Interface) error {
+				_, _, err := IDAStarContext(context.Background(), p)
+				return err
+			},
+		} {
+			err := search(&faultyNumber{fault: callback})
+			var perr *PanicError
+			if !errors.As(err, &perr) {
+				t.Errorf("%s, %s: got %v, want a *PanicError", name, callback, err)
+				continue
+			}
+			var state interface{} = number(3)
+			if callback == "Start" {
+				state = nil
+			}
+			if perr.Callback != callback || perr.State != state {
+				t.Errorf("%s, %s: got %s() at %v", name, callback, perr.Callback, perr.State)
+			}
+			if !strings.Contains(string(perr.Stack), "faultyNumber") || !strings.Contains(err.Error(), callback) {
+				t.Errorf("%s, %s: got %q", name, callback, err)
+			}
+
+			// The failed type assertion is wrapped.
+			var rerr runtime.Error
+			if isRuntime := errors.As(err, &rerr); isRuntime != (callback == "Cost") {
+				t.Errorf("%s, %s: got runtime error %v", name, callback, rerr)
+			}
+		}
+	}
+
+	var perr *PanicError
+	_, err := SearchResult[[2]int](context.Background(), faultyGrid{3})
+	if !errors.As(err, &perr) || perr.Callback != "Heuristic" || perr.State != [2]int{0, 1} || perr.Value != "Heuristic failed" {
+		t.Errorf("Problem: got %v", err)
+	}
+
+	test := BasicTests[1]
+	g := faultyReversible{reversibleGraph{statelessGraph{test.g.edges, test.out[:1], test.out[len(test.out)-1:]}}}
+	_, err = SearchBidirectional[string](context.Background(), g)
+	if !errors.As(err, &perr) || perr.Callback != "Predecessors" || perr.State != test.out[len(test.out)-1:] {
+		t.Errorf("bidirectional: got %v", err)
+	}
+	_, err = SearchAnytime[[2]int](context.Background(), faultyGrid{3}, 2, 0.5, nil)
+	if !errors.As(err, &perr) || perr.Callback != "Heuristic" {
+		t.Errorf("anytime: got %v", err)
+	}
+	d := faultyDynamic{dynamicGrid{newWallGrid(5, 0, 1)}}
+	_, err = NewPlanner[[2]int](d, d.Start(), [2]int{4, 4}).Plan(context.Background())
+	if !errors.As(err, &perr) || perr.Callback != "Predecessors" || perr.State != [2]int{4, 4} {
+		t.Errorf("planner: got %v", err)
+	}
+
+	// Keys are user code as well.
+	key := Key(func(x interface{}) interface{} { panic("Key failed") })
+	if _, _, err := SearchContext(context.Background(), &pair{}, key); !errors.As(err, &perr) || perr.Callback != "Key" {
+		t.Errorf("key: got %v", err)
+	}
+
+	// Including the ones Yen's algorithm calls between the searches.
+	for n := 0; n < 300; n += 7 {
+		calls := 0
+		key := Key(func(x interface{}) interface{} {
+			if calls++; calls > n {
+				panic("Key failed")
+			}
+			return fmt.Sprint(x)
+		})
+		if _, err := KShortestPaths[interface{}](context.Background(), Adapt(&pair{}), 3, key); !errors.As(err, &perr) || perr.Callback != "Key" {
+			t.Errorf("KShortestPaths, key failing after %d calls: got %v", n, err)
+		}
+	}
+	var uerr *UncomparableError
+	if _, err := KShortestPaths[interface{}](context.Background(), Adapt(&pair{}), 3); !errors.As(err, &uerr) {
+		t.Errorf("KShortestPaths, uncomparable: got %v", err)
+	}
+
+	// Uncomparable states or keys are errors rather than panics.
+	for _, tt := range []struct {
+		name     string
+		p        Interface
+		opts     []Option
+		callback string
+	}{
+		{"start", &pair{}, nil, "Start"},
+		{"successor", &faultyNumber{fault: "uncomparable"}, nil, "Successors"},
+		{"key", &pair{}, []Option{Key(func(x interface{}) interface{} { return x })}, "Key"},
+	} {
+		var uerr *UncomparableError
+		if _, _, err := SearchContext(context.Background(), tt.p, tt.opts...); !errors.As(err, &uerr) || uerr.Callback != tt.callback {
+			t.Errorf("uncomparable %s: got %v", tt.name, err)
+		}
+	}
+
+	// Recovery is off in Search and when turned off.
+	if !panics(func() { Search(&faultyNumber{fault: "Cost"}) }) {
+		t.Errorf("Search: got no panic")
+	}
+	if !panics(func() { SearchResult[[2]int](context.Background(), faultyGrid{3}, RecoverPanics(false)) }) {
+		t.Errorf("RecoverPanics(false): got no panic")
+	}
+
+	// Panics outside the problem are not recovered.
+	obs := Observe(Observer[interface{}]{Expand: func(e Event[interface{}]) { panic("Expand failed") }})
+	if !panics(func() { SearchContext(context.Background(), &faultyNumber{}, obs) }) {
+		t.Errorf("observer: got no panic")
+	}
+}
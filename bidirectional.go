@@ -0,0 +1,184 @@
+package astar
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+)
+
+// Reversible is a Problem which can also be searched backwards,
+// from its only final state to the start state.
+type Reversible[S comparable] interface {
+	Problem[S]
+
+	// The final state.
+	Goal() S
+
+	// Moves into the given state with their path costs: Edge.To is
+	// the state the move is made from.
+	Predecessors(S) []Edge[S]
+
+	// Heuristic estimate of “how far from the start?” for the given state.
+	HeuristicFromStart(S) float64
+}
+
+// SearchBidirectional finds the shortest path by searching forwards from
+// p.Start() and backwards from p.Goal() at the same time until the searches
+// meet. On large graphs it usually explores far fewer states than Search.
+//
+// Both heuristic estimates must be consistent. The searches are ordered by
+// the average of them, (Heuristic() - HeuristicFromStart()) / 2 forwards and
+// its negation backwards, which keeps path cost bounds of the two searches
+// comparable. The search stops as soon as the sum of the smallest values
+// on both priority queues reaches the cost of the best path found, so the
+// path returned is the shortest one.
+//
+// SearchBidirectional respects the MaxExpanded(), MaxFrontier(), LastSteps()
+// and RecoverPanics() options. The Result's Steps are the states explored by
+// both searches in the order they have been explored. States are compared
+// with ==: ErrKeysUnsupported is returned for a Key() option or a Keyer.
+func SearchBidirectional[S comparable](ctx context.Context, p Reversible[S], opts ...Option) (res *Result[S], err error) {
+	c := newContextConfig(opts)
+	res = &Result[S]{}
+	begin := time.Now()
+	defer func() { res.Duration = time.Since(begin) }()
+
+	done := ctx.Done()
+
+	steps := newTrail[S](c.lastSteps)
+	defer func() { res.Steps = steps.slice() }()
+
+	if err := noKeys[S](c, p); err != nil {
+		return res, err
+	}
+
+	// Return panics in p's methods as errors.
+	if c.recoverPanics {
+		g := newGuard[S](p)
+		p = g
+		defer g.catch(&err)
+	}
+
+	potential := func(s S) float64 { return (p.Heuristic(s) - p.HeuristicFromStart(s)) / 2 }
+
+	// One of the two searches: forwards with the potential as an estimate,
+	// or backwards with the negated potential.
+	type side struct {
+		pq        states[S]
+		seen      map[S]*state[S]
+		moves     func(S) []Edge[S]
+		potential func(S) float64
+	}
+	newSide := func(start S, moves func(S) []Edge[S], potential func(S) float64) *side {
+		s := &side{seen: map[S]*state[S]{}, moves: moves, potential: potential}
+		first := &state[S]{state: start, estimate: potential(start)}
+		s.seen[start] = first
+		heap.Push(&s.pq, first)
+		return s
+	}
+	forward := newSide(p.Start(), p.Neighbors, potential)
+	backward := newSide(p.Goal(), p.Predecessors, func(s S) float64 { return -potential(s) })
+	res.Generated, res.MaxFrontier = 2, 2
+
+	// The best path found so far goes through the meeting state.
+	best := math.Inf(1)
+	var (
+		meeting S
+		met     bool
+	)
+	if start, ok := backward.seen[p.Start()]; ok {
+		best, meeting, met = 0, start.state, true
+	}
+
+	top := func(s *side) float64 {
+		if s.pq.Empty() {
+			return math.Inf(1)
+		}
+		return s.pq.items[0].cost + s.pq.items[0].estimate
+	}
+
+	for !forward.pq.Empty() && !backward.pq.Empty() {
+		if top(forward)+top(backward) >= best {
+			break
+		}
+
+		select {
+		case <-done:
+			return res, ctx.Err()
+		default:
+		}
+		if c.maxExpanded > 0 && res.Expanded >= c.maxExpanded {
+			return res, &LimitError{"expanded", c.maxExpanded}
+		}
+
+		// Expand the side with the smaller value.
+		this, other := forward, backward
+		if top(backward) < top(forward) {
+			this, other = backward, forward
+		}
+
+		current := heap.Pop(&this.pq).(*state[S])
+		current.explored = true
+		res.Expanded++
+		steps.add(current.state)
+
+		for _, edge := range this.moves(current.state) {
+			cost := current.cost + edge.Cost
+
+			succ, ok := this.seen[edge.To]
+			switch {
+			case !ok:
+				succ = &state[S]{state: edge.To, cost: cost, estimate: this.potential(edge.To), parent: current}
+				this.seen[edge.To] = succ
+				heap.Push(&this.pq, succ)
+				res.Generated++
+				if n := forward.pq.Len() + backward.pq.Len(); n > res.MaxFrontier {
+					res.MaxFrontier = n
+				}
+				if c.maxFrontier > 0 && res.MaxFrontier > c.maxFrontier {
+					return res, &LimitError{"frontier", c.maxFrontier}
+				}
+			case !succ.explored && cost < succ.cost:
+				succ.cost, succ.parent = cost, current
+				heap.Fix(&this.pq, succ.index)
+				res.Updated++
+			default:
+				continue
+			}
+
+			// Join the paths if the other search has reached the state.
+			if there, ok := other.seen[edge.To]; ok && succ.cost+there.cost < best {
+				best = succ.cost + there.cost
+				meeting, met = succ.state, true
+			}
+		}
+	}
+
+	if !met {
+		return res, ErrNotFound
+	}
+
+	// Forward half of the path up to the meeting state, reversed.
+	for curr := forward.seen[meeting]; curr != nil; curr = curr.parent {
+		res.Nodes = append(res.Nodes, Node[S]{State: curr.state, G: curr.cost})
+	}
+	for i, j := 0, len(res.Nodes)-1; i < j; i, j = i+1, j-1 {
+		res.Nodes[i], res.Nodes[j] = res.Nodes[j], res.Nodes[i]
+	}
+
+	// Backward half after the meeting state.
+	for curr := backward.seen[meeting].parent; curr != nil; curr = curr.parent {
+		res.Nodes = append(res.Nodes, Node[S]{State: curr.state, G: best - curr.cost})
+	}
+
+	for i := range res.Nodes {
+		node := &res.Nodes[i]
+		node.H = p.Heuristic(node.State)
+		node.F = node.G + node.H
+		res.Path = append(res.Path, node.State)
+	}
+	res.Cost = best
+
+	return res, nil
+}
@@ -0,0 +1,220 @@
+// Benchmark A* search on DIMACS road networks.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/dimacs"
+	"github.com/pietv/astar/graph"
+	"github.com/pietv/astar/landmarks"
+)
+
+var (
+	// Command line flags.
+	grFlag            = flag.String("gr", "", "read arcs from .gr FILE")
+	coFlag            = flag.String("co", "", "read coordinates from .co FILE")
+	queriesFlag       = flag.Int("queries", 10, "run N random queries")
+	seedFlag          = flag.Int64("seed", 1, "random query seed")
+	pairsFlag         = flag.String("pairs", "", "read query pairs from FILE")
+	dijkstraFlag      = flag.Bool("dijkstra", false, "ignore coordinates")
+	bidirectionalFlag = flag.Bool("bidirectional", false, "use bidirectional search")
+	landmarksFlag     = flag.Int("landmarks", 0, "use N landmarks")
+	lmfileFlag        = flag.String("lmfile", "", "load landmarks from FILE, or save them there")
+)
+
+func usage() {
+	program := filepath.Base(os.Args[0])
+	usage := `dimacs: benchmark A* search on a DIMACS road network.
+Usage: dimacs -gr FILE [-co FILE] [-queries N] [-seed N] [-pairs FILE]
+              [-dijkstra] [-bidirectional] [-landmarks N [-lmfile FILE]] [-help]
+
+Reads a network in the 9th DIMACS Implementation Challenge format
+and reports the path cost, expanded nodes and time of every query.
+
+Flags:
+  -gr FILE          read arcs from a .gr FILE.
+  -co FILE          read coordinates from a .co FILE for the heuristic;
+                    without it, the search is Dijkstra's algorithm.
+
+  -queries N        run N queries between random nodes (default 10).
+  -seed N           random query seed (default 1).
+  -pairs FILE       read queries from FILE instead, one “SOURCE TARGET”
+                    pair of node numbers per line.
+
+  -dijkstra         ignore coordinates.
+  -bidirectional    search from both ends.
+  -landmarks N      precompute N landmarks for the ALT heuristic.
+  -lmfile FILE      load landmarks from FILE if it exists,
+                    or save them there.
+
+  -help             show this help.
+
+Examples:
+  ` + program + ` -gr USA-road-d.NY.gr -co USA-road-d.NY.co -queries 100
+  ` + program + ` -gr USA-road-d.NY.gr -co USA-road-d.NY.co -dijkstra -seed 7
+  ` + program + ` -gr USA-road-d.NY.gr -landmarks 16 -lmfile NY.landmarks`
+
+	fmt.Println(usage)
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if *grFlag == "" {
+		usage()
+	}
+
+	start := time.Now()
+	g, err := dimacs.Load(*grFlag, *coFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read the network: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Read %d nodes in %v, heuristic scale %g\n\n", g.Len(), time.Since(start).Round(time.Millisecond), g.Scale)
+
+	if *dijkstraFlag {
+		g.Scale = 0
+	}
+
+	var l *landmarks.Landmarks[int]
+	if *landmarksFlag > 0 || *lmfileFlag != "" {
+		if *bidirectionalFlag {
+			fmt.Fprintf(os.Stderr, "Landmarks don't work with bidirectional search.\n")
+			os.Exit(1)
+		}
+
+		start := time.Now()
+		l, err = loadLandmarks(g)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot compute landmarks: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Got %d landmarks in %v\n\n", len(l.Landmarks), time.Since(start).Round(time.Millisecond))
+	}
+
+	var pairs [][2]int
+	if *pairsFlag != "" {
+		pairs, err = readPairs(*pairsFlag, g.Len())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read queries from %q: %s\n", *pairsFlag, err)
+			os.Exit(1)
+		}
+	} else {
+		r := rand.New(rand.NewSource(*seedFlag))
+		for i := 0; i < *queriesFlag; i++ {
+			pairs = append(pairs, [2]int{1 + r.Intn(g.Len()), 1 + r.Intn(g.Len())})
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "#\tsource\ttarget\tcost\texpanded\ttime\t")
+
+	var (
+		found    int
+		expanded int
+		total    time.Duration
+	)
+	for i, pair := range pairs {
+		res, err := query(g, l, pair[0], pair[1])
+		if err != nil {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%s\t\t\t\n", i+1, pair[0], pair[1], err)
+			continue
+		}
+		found++
+		expanded += res.Expanded
+		total += res.Duration
+		fmt.Fprintf(w, "%d\t%d\t%d\t%g\t%d\t%v\t\n", i+1, pair[0], pair[1], res.Cost, res.Expanded, res.Duration.Round(time.Microsecond))
+	}
+	w.Flush()
+
+	if found > 0 {
+		fmt.Printf("\nFound %d of %d paths, %d nodes expanded and %v per path on average\n",
+			found, len(pairs), expanded/found, (total / time.Duration(found)).Round(time.Microsecond))
+	}
+}
+
+func query(g *graph.Graph[int], l *landmarks.Landmarks[int], source, target int) (*astar.Result[int], error) {
+	g.From, g.To = source, target
+
+	// Only the paths and the counts are of interest, not the explored nodes.
+	steps := astar.LastSteps(0)
+	switch {
+	case *bidirectionalFlag:
+		return astar.SearchBidirectional[int](context.Background(), g, steps)
+	case l != nil:
+		return astar.SearchResult[int](context.Background(), l.Problem(g, target), steps)
+	}
+	return astar.SearchResult[int](context.Background(), g, steps)
+}
+
+// loadLandmarks reads landmarks from -lmfile if it exists, or computes
+// them and saves them there.
+func loadLandmarks(g *graph.Graph[int]) (*landmarks.Landmarks[int], error) {
+	if *lmfileFlag != "" {
+		l, err := landmarks.LoadFile[int](*lmfileFlag)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return l, err
+		}
+	}
+
+	if *landmarksFlag <= 0 {
+		return nil, fmt.Errorf("%q does not exist and -landmarks is not set", *lmfileFlag)
+	}
+	l, err := landmarks.Build[int](g, g.Nodes(), *landmarksFlag, landmarks.Farthest, *seedFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	if *lmfileFlag != "" {
+		if err := l.SaveFile(*lmfileFlag); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// readPairs reads query pairs of node numbers between 1 and n.
+func readPairs(filename string, n int) ([][2]int, error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	var pairs [][2]int
+	s := bufio.NewScanner(in)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected “SOURCE TARGET”", line)
+		}
+
+		var pair [2]int
+		for i := range pair {
+			pair[i], err = strconv.Atoi(fields[i])
+			if err != nil || pair[i] < 1 || pair[i] > n {
+				return nil, fmt.Errorf("line %d: bad node %q", line, fields[i])
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, s.Err()
+}
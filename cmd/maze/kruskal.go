@@ -120,6 +120,7 @@ func newRandomKruskal(rows, cols int) *maze {
 
 	return &maze{
 		maze:   m,
+		grid:   walls(m),
 		start:  start,
 		finish: finish,
 		curr:   start,
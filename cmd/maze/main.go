@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/pietv/astar"
+	"github.com/pietv/astar/heuristics"
+	"github.com/pietv/astar/jps"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -29,6 +31,8 @@ var (
 	demoFlag      = flag.Int("demo", 0, "run demo #")
 	randomFlag    = flag.Bool("random", false, "generate a random maze")
 	sizeFlag      = flag.String("size", defaultSize, "generate a random maze of size NxM")
+	replanFlag    = flag.Int("replan", 0, "block the path with walls N times and replan")
+	jpsFlag       = flag.Bool("jps", false, "use Jump Point Search")
 )
 
 func usage() {
@@ -36,6 +40,7 @@ func usage() {
 	usage := `maze: demonstrate A* search algorithm traversing a maze.
 Usage: maze [FILE] [-demo N] [-random] [-size NxM] [-help]
             [-euclid|-manhattan] [-cost MULTIPLIER] [-estimate MULTIPLIER]
+            [-replan N] [-jps]
 
 With no FILE, use a demo or a random maze.
 
@@ -49,12 +54,19 @@ Flags:
   -estimate MULTIPLIER    multiply estimate value by MULTIPLIER.
   -cost MULTIPLIER        multiply cost value by MULTIPLIER.
 
+  -replan N               put a wall on the path N times, each time repairing
+                          the path incrementally with D* Lite.
+  -jps                    use Jump Point Search instead of A* and compare
+                          the number of explored cells.
+
   -help                   show this help.
 
 Examples:
   ` + program + ` -size 2x40                      - long random maze
   ` + program + ` -demo 2 -euclid -estimate 0.5   - euclid distance with custom estimate
-  ` + program + ` -random -cost 0                 - random maze with greedy traversal`
+  ` + program + ` -random -cost 0                 - random maze with greedy traversal
+  ` + program + ` -demo 2 -replan 3               - incremental replanning
+  ` + program + ` -demo 1 -jps                    - jump point search`
 
 	fmt.Println(usage)
 	os.Exit(2)
@@ -138,11 +150,11 @@ func main() {
 	}
 
 	// By default use Manhattan distance.
+	metric := heuristics.Manhattan
 	if *euclidFlag {
-		estimateFunc = genEuclidEstimate(*estimateFlag)
-	} else {
-		estimateFunc = genManhattanEstimate(*estimateFlag)
+		metric = heuristics.Euclidean
 	}
+	estimate := heuristics.Weighted(*estimateFlag, heuristics.Distance(metric, position, interface{}(maze.finish)))
 
 	// Don't use fancy colorings if output is redirected.
 	var medium string
@@ -152,12 +164,37 @@ func main() {
 		medium = "File"
 	}
 
-	path, steps, err := astar.Search(maze)
+	tmpl := template.Must(template.New("Maze").Funcs(helpers).Parse(terminalTmpl))
+
+	if *replanFlag > 0 {
+		titles, mazes := replan(maze, *replanFlag)
+		for i := range titles {
+			tmpl.ExecuteTemplate(os.Stdout, medium, struct {
+				Title string
+				Maze  [][]string
+			}{
+				Title: title + ". " + titles[i],
+				Maze:  mazes[i],
+			})
+		}
+		return
+	}
+
+	path, steps, err := astar.Search(heuristics.WithInterface(maze, estimate))
 	if err != nil {
 		title = "Yikes! Could not find the path for this one"
 	}
 
-	template.Must(template.New("Maze").Funcs(helpers).Parse(terminalTmpl)).ExecuteTemplate(os.Stdout, medium, struct {
+	if *jpsFlag {
+		res, err := jps.Search(maze.grid, jps.Point{Row: maze.start.i, Col: maze.start.j},
+			jps.Point{Row: maze.finish.i, Col: maze.finish.j}, false)
+		if err == nil {
+			title += fmt.Sprintf(". Jump Point Search explored %d cells, A* explored %d", len(res.Steps), len(steps))
+		}
+		path, steps = points(res.Path), points(res.Steps)
+	}
+
+	tmpl.ExecuteTemplate(os.Stdout, medium, struct {
 		Title string
 		Maze  [][]string
 	}{
@@ -2,9 +2,12 @@ package main
 
 import (
 	"fmt"
-	"math"
 	"os/exec"
 	"strings"
+
+	"github.com/pietv/astar/grid"
+	"github.com/pietv/astar/heuristics"
+	"github.com/pietv/astar/jps"
 )
 
 // Maze drawing sequences.
@@ -59,44 +62,22 @@ var (
 			return out
 		},
 	}
-
-	estimateFunc = genEuclidEstimate(*estimateFlag)
 )
 
-// genManhattanEstimate generates a Manhattan distance Estimate() function
-// with a custom multiplier.
-func genManhattanEstimate(multiplier float64) func(interface{}, interface{}) float64 {
-	return func(finish, neighbor interface{}) float64 {
-		var (
-			aI = float64(neighbor.(location).i)
-			bI = float64(finish.(location).i)
-			aJ = float64(neighbor.(location).j)
-			bJ = float64(finish.(location).j)
-		)
-		return (math.Abs(aI-bI) + math.Abs(aJ-bJ)) * multiplier
-	}
+type location struct {
+	i, j int
 }
 
-// genEuclidEstimate generates a Euclidean distance Estimate() function with a
-// custom multiplier.
-func genEuclidEstimate(multiplier float64) func(interface{}, interface{}) float64 {
-	return func(finish, neighbor interface{}) float64 {
-		var (
-			aI = float64(neighbor.(location).i)
-			bI = float64(finish.(location).i)
-			aJ = float64(neighbor.(location).j)
-			bJ = float64(finish.(location).j)
-		)
-		return math.Sqrt(math.Pow(aI-bI, 2)+math.Pow(aJ-bJ, 2)) * multiplier
-	}
-}
+func (l location) point() grid.Point { return grid.Point{Row: l.i, Col: l.j} }
 
-type location struct {
-	i, j int
+// position locates a maze state for distance heuristics.
+func position(state interface{}) heuristics.Point {
+	return grid.Location(state.(location).point())
 }
 
 type maze struct {
 	maze                [][]string
+	grid                *grid.Grid
 	start, finish, curr location
 }
 
@@ -105,39 +86,28 @@ func (m maze) Finish() bool                      { return m.curr == m.finish }
 func (m *maze) Move(t interface{})               { m.curr = t.(location) }
 func (m maze) Cost(neighbor interface{}) float64 { return *costFlag }
 
+// Estimate is Manhattan distance; main replaces it as set by the flags.
 func (m maze) Estimate(neighbor interface{}) float64 {
-	return estimateFunc(m.finish, neighbor)
+	return heuristics.Manhattan(position(neighbor), position(m.finish))
 }
 
 func (m maze) Successors() []interface{} {
 	successors := []interface{}{}
-
-	checkLocation := func(i, j int) {
-		// The matrix is not necessarily rectangular.
-		if i < 0 || j < 0 || i >= len(m.maze) || j >= len(m.maze[i]) {
-			return
-		}
-
-		switch m.maze[i][j] {
-		case spaceRune, finishRune:
-			successors = append(successors, location{i, j})
-		}
+	for _, l := range m.moves(m.curr) {
+		successors = append(successors, l)
 	}
-
-	i, j := m.curr.i, m.curr.j
-
-	// North.
-	checkLocation(i-1, j)
-	// South.
-	checkLocation(i+1, j)
-	// West.
-	checkLocation(i, j-1)
-	// East.
-	checkLocation(i, j+1)
-
 	return successors
 }
 
+// moves returns open locations next to the given one.
+func (m maze) moves(l location) []location {
+	moves := []location{}
+	for _, edge := range m.grid.Neighbors(l.point()) {
+		moves = append(moves, location{edge.To.Row, edge.To.Col})
+	}
+	return moves
+}
+
 // tput controls the terminal using tput(1). Command is a capability name and
 // params are either strings or integers. Does nothing if a capability
 // or tput(1) is not available. See terminfo(5) and infocmp(1) for a list of
@@ -183,6 +153,7 @@ func new(lines []string) *maze {
 
 	return &maze{
 		maze:   m,
+		grid:   walls(m),
 		start:  start,
 		finish: finish,
 		curr:   start,
@@ -217,3 +188,40 @@ func (m *maze) drawMaze(path, steps []interface{}) [][]string {
 
 	return maze
 }
+
+// walls returns a grid with the walls of a maze. The matrix is not
+// necessarily rectangular, the missing cells are walls.
+func walls(m [][]string) *grid.Grid {
+	cols := 0
+	for _, row := range m {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	g := grid.New(len(m), cols)
+	for i := range m {
+		for j := 0; j < cols; j++ {
+			if j >= len(m[i]) {
+				g.SetWall(grid.Point{Row: i, Col: j})
+				continue
+			}
+
+			switch m[i][j] {
+			case spaceRune, startRune, finishRune:
+			default:
+				g.SetWall(grid.Point{Row: i, Col: j})
+			}
+		}
+	}
+	return g
+}
+
+// points converts jump point search cells to locations for drawMaze.
+func points(cells []jps.Point) []interface{} {
+	out := make([]interface{}, len(cells))
+	for i, p := range cells {
+		out[i] = location{p.Row, p.Col}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/heuristics"
+)
+
+// dynamicMaze is a maze for incremental replanning with astar.Planner.
+// Walls may be added to the maze in between the calls of Plan().
+type dynamicMaze struct {
+	*maze
+}
+
+func (m dynamicMaze) Neighbors(l location) []astar.Edge[location] {
+	edges := []astar.Edge[location]{}
+	for _, next := range m.moves(l) {
+		edges = append(edges, astar.Edge[location]{To: next, Cost: *costFlag})
+	}
+	return edges
+}
+
+// Moves are reversible, so predecessors are the same as neighbors.
+func (m dynamicMaze) Predecessors(l location) []astar.Edge[location] {
+	return m.Neighbors(l)
+}
+
+// Distance is Manhattan distance, which is consistent for the maze moves
+// as opposed to the multiplied estimates used by Search.
+func (m dynamicMaze) Distance(from, to location) float64 {
+	return heuristics.Manhattan(position(from), position(to)) * *costFlag
+}
+
+// replan finds a path through the maze, then blocks it with a wall
+// n times, each time repairing the path with astar.Planner.
+// It returns titles and drawings of each step.
+func replan(m *maze, n int) ([]string, [][][]string) {
+	var (
+		titles []string
+		mazes  [][][]string
+	)
+
+	planner := astar.NewPlanner[location](dynamicMaze{m}, m.start, m.finish)
+
+	res, err := planner.Plan(context.Background())
+	for i := 0; ; i++ {
+		switch {
+		case err != nil:
+			titles = append(titles, "Yikes! The path is blocked")
+		case i == 0:
+			titles = append(titles, fmt.Sprintf("Initial path. Explored %d cells", res.Expanded))
+		default:
+			titles = append(titles, fmt.Sprintf("Wall #%d. Re-explored %d cells", i, res.Expanded))
+		}
+		mazes = append(mazes, m.drawMaze(states(res.Path), states(res.Steps)))
+
+		if err != nil || i == n || len(res.Path) < 3 {
+			break
+		}
+
+		// Put a wall on a random location along the path
+		// and tell the planner which locations can't go there anymore.
+		wall := res.Path[1+rand.Intn(len(res.Path)-2)]
+		m.maze[wall.i][wall.j] = wallRune
+		m.grid.SetWall(wall.point())
+		planner.UpdateEdges(m.moves(wall)...)
+
+		res, err = planner.Plan(context.Background())
+	}
+
+	return titles, mazes
+}
+
+// states converts locations to a slice used by drawMaze.
+func states(locations []location) []interface{} {
+	out := make([]interface{}, len(locations))
+	for i, l := range locations {
+		out[i] = l
+	}
+	return out
+}
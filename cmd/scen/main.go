@@ -0,0 +1,123 @@
+// Run Moving AI grid pathfinding benchmark scenarios.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pietv/astar/grid"
+	"github.com/pietv/astar/movingai"
+)
+
+var (
+	// Command line flags.
+	mapsFlag    = flag.String("maps", "", "read maps from DIR")
+	verboseFlag = flag.Bool("v", false, "list failed scenarios")
+)
+
+func usage() {
+	program := filepath.Base(os.Args[0])
+	usage := `scen: run Moving AI grid pathfinding benchmark scenarios.
+Usage: scen [-maps DIR] [-v] FILE.scen... [-help]
+
+Searches every scenario with A*, checks the path costs against
+the optimal lengths, and reports the expanded cells and time
+per bucket. Exits with status 1 if any scenario fails.
+
+Flags:
+  -maps DIR    read the maps named in scenarios from DIR
+               (default: the directory of each scenario file).
+  -v           list failed scenarios.
+
+  -help        show this help.
+
+Examples:
+  ` + program + ` dao/arena.map.scen
+  ` + program + ` -maps dao-map dao-scen/*.scen`
+
+	fmt.Println(usage)
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	failed := false
+	for _, filename := range flag.Args() {
+		scenarios, err := movingai.LoadScenarios(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read scenarios from %q: %s\n", filename, err)
+			os.Exit(1)
+		}
+
+		dir := *mapsFlag
+		if dir == "" {
+			dir = filepath.Dir(filename)
+		}
+		maps := func(name string) (*grid.Grid, error) {
+			return movingai.LoadMap(filepath.Join(dir, filepath.Base(name)))
+		}
+
+		buckets, err := movingai.Run(context.Background(), scenarios, maps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot run scenarios from %q: %s\n", filename, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s\n\n", filename)
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "bucket\tscenarios\tfailed\texpanded\ttime\t")
+
+		var total movingai.Bucket
+		for _, b := range buckets {
+			row(w, fmt.Sprint(b.Bucket), b)
+			total.Scenarios += b.Scenarios
+			total.Failures = append(total.Failures, b.Failures...)
+			total.Expanded += b.Expanded
+			total.Duration += b.Duration
+		}
+		row(w, "total", total)
+		w.Flush()
+
+		if *verboseFlag {
+			for _, f := range total.Failures {
+				// Coordinates are x, y as in scenario files.
+				fmt.Printf("  bucket %d: (%d, %d) to (%d, %d): ",
+					f.Bucket, f.Start.Col, f.Start.Row, f.Goal.Col, f.Goal.Row)
+				if f.Err != nil {
+					fmt.Println(f.Err)
+				} else {
+					fmt.Printf("cost %.8f, optimal %.8f\n", f.Cost, f.Optimal)
+				}
+			}
+		}
+		fmt.Println()
+
+		failed = failed || len(total.Failures) > 0
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// row writes a table row with the averages of the scenarios in a bucket.
+func row(w *tabwriter.Writer, name string, b movingai.Bucket) {
+	n := b.Scenarios - len(b.Failures)
+	if n <= 0 {
+		fmt.Fprintf(w, "%s\t%d\t%d\t\t\t\n", name, b.Scenarios, len(b.Failures))
+		return
+	}
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%v\t\n", name, b.Scenarios, len(b.Failures),
+		b.Expanded/n, (b.Duration / time.Duration(n)).Round(time.Microsecond))
+}
@@ -0,0 +1,194 @@
+// Package dimacs reads road networks in the format of the 9th DIMACS
+// Implementation Challenge on shortest paths:
+// http://www.diag.uniroma1.it/challenge9/format.shtml.
+//
+// A network is an .gr file with arcs,
+//
+//	c Comment.
+//	p sp 3 2
+//	a 1 2 10
+//	a 2 3 7
+//
+// and an optional .co file with node coordinates,
+//
+//	p aux sp co 3
+//	v 1 -73530767 41085396
+//	v 2 -73530538 41086098
+//	v 3 -73519366 41048796
+//
+// Nodes are numbered from 1. The coordinates make an admissible
+// heuristic for A* (see graph.Graph.FitScale).
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pietv/astar/graph"
+	"github.com/pietv/astar/heuristics"
+)
+
+// Read returns a directed graph with the arcs from gr and, unless co is nil,
+// the coordinates from co. Of parallel arcs, the cheapest one is kept.
+// With coordinates, the graph's Scale is fitted to the arc costs.
+func Read(gr, co io.Reader) (*graph.Graph[int], error) {
+	g := graph.New[int](true)
+
+	var n, m, arcs int
+	err := scan(gr, func(fields []string) error {
+		switch fields[0] {
+		case "p":
+			if len(fields) != 4 || fields[1] != "sp" {
+				return fmt.Errorf("expected “p sp NODES ARCS”")
+			}
+			var err error
+			if n, err = strconv.Atoi(fields[2]); err != nil || n < 0 {
+				return fmt.Errorf("bad node count %q", fields[2])
+			}
+			if m, err = strconv.Atoi(fields[3]); err != nil || m < 0 {
+				return fmt.Errorf("bad arc count %q", fields[3])
+			}
+			for v := 1; v <= n; v++ {
+				g.AddNode(v)
+			}
+		case "a":
+			if len(fields) != 4 {
+				return fmt.Errorf("expected “a FROM TO COST”")
+			}
+			from, err := node(fields[1], n)
+			if err != nil {
+				return err
+			}
+			to, err := node(fields[2], n)
+			if err != nil {
+				return err
+			}
+			cost, err := strconv.ParseFloat(fields[3], 64)
+			if err != nil || cost < 0 {
+				return fmt.Errorf("bad arc cost %q", fields[3])
+			}
+			if !parallel(g, from, to, cost) {
+				g.AddEdge(from, to, cost)
+			}
+			arcs++
+		default:
+			return fmt.Errorf("unknown line type %q", fields[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dimacs: graph %w", err)
+	}
+	if arcs != m {
+		return nil, fmt.Errorf("dimacs: graph has %d arcs, expected %d", arcs, m)
+	}
+
+	if co == nil {
+		return g, nil
+	}
+
+	var coords int
+	err = scan(co, func(fields []string) error {
+		switch fields[0] {
+		case "p":
+			if len(fields) != 5 || fields[1] != "aux" || fields[2] != "sp" || fields[3] != "co" {
+				return fmt.Errorf("expected “p aux sp co NODES”")
+			}
+			if fields[4] != strconv.Itoa(n) {
+				return fmt.Errorf("%s nodes, the graph has %d", fields[4], n)
+			}
+		case "v":
+			if len(fields) != 4 {
+				return fmt.Errorf("expected “v NODE X Y”")
+			}
+			v, err := node(fields[1], n)
+			if err != nil {
+				return err
+			}
+			x, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return fmt.Errorf("bad coordinate %q", fields[2])
+			}
+			y, err := strconv.ParseFloat(fields[3], 64)
+			if err != nil {
+				return fmt.Errorf("bad coordinate %q", fields[3])
+			}
+			g.SetCoord(v, heuristics.Point{X: x, Y: y})
+			coords++
+		default:
+			return fmt.Errorf("unknown line type %q", fields[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dimacs: coordinates %w", err)
+	}
+	if coords != n {
+		return nil, fmt.Errorf("dimacs: %d coordinates for %d nodes", coords, n)
+	}
+
+	g.FitScale()
+	return g, nil
+}
+
+// Load reads a graph from the named .gr file and, unless coFile is empty,
+// the coordinates from the named .co file.
+func Load(grFile, coFile string) (*graph.Graph[int], error) {
+	gr, err := os.Open(grFile)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	if coFile == "" {
+		return Read(bufio.NewReader(gr), nil)
+	}
+
+	co, err := os.Open(coFile)
+	if err != nil {
+		return nil, err
+	}
+	defer co.Close()
+
+	return Read(bufio.NewReader(gr), bufio.NewReader(co))
+}
+
+// scan calls f with the fields of every line that is not empty
+// or a comment, until it returns an error.
+func scan(r io.Reader, f func(fields []string) error) error {
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if err := f(fields); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return s.Err()
+}
+
+// parallel tells whether there is an arc from one node to another
+// that is not more expensive, so that the parallel arc can be skipped.
+func parallel(g *graph.Graph[int], from, to int, cost float64) bool {
+	for _, e := range g.Neighbors(from) {
+		if e.To == to && e.Cost <= cost {
+			return true
+		}
+	}
+	return false
+}
+
+// node parses a node number between 1 and n.
+func node(field string, n int) (int, error) {
+	v, err := strconv.Atoi(field)
+	if err != nil || v < 1 || v > n {
+		return 0, fmt.Errorf("bad node %q", field)
+	}
+	return v, nil
+}
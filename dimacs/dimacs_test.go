@@ -0,0 +1,111 @@
+package dimacs_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/pietv/astar"
+	. "github.com/pietv/astar/dimacs"
+)
+
+var (
+	gr = `c A square with a shortcut.
+p sp 4 6
+a 1 2 10
+a 2 3 10
+a 3 4 10
+a 1 4 50
+a 1 4 35
+a 4 1 10
+`
+	co = `c Corners.
+p aux sp co 4
+v 1 0 0
+v 2 10 0
+v 3 10 10
+v 4 0 10
+`
+)
+
+func TestRead(t *testing.T) {
+	g, err := Read(strings.NewReader(gr), strings.NewReader(co))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Len() != 4 || !g.Directed() || g.Scale != 1 {
+		t.Errorf("got %d nodes, directed %v, scale %v", g.Len(), g.Directed(), g.Scale)
+	}
+
+	g.From, g.To = 1, 4
+	res, err := astar.SearchResult[int](context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(res.Path) != "[1 2 3 4]" || res.Cost != 30 {
+		t.Errorf("got %v of cost %v", res.Path, res.Cost)
+	}
+
+	g.From, g.To = 4, 3
+	if res, err = astar.SearchResult[int](context.Background(), g); err != nil || res.Cost != 30 {
+		t.Errorf("got %v, %v; expected cost 30", res, err)
+	}
+}
+
+// The heuristic is admissible however the arc costs relate to coordinates.
+func TestScale(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		var gr, co strings.Builder
+		fmt.Fprintf(&gr, "p sp 30 100\n")
+		fmt.Fprintf(&co, "p aux sp co 30\n")
+		for v := 1; v <= 30; v++ {
+			fmt.Fprintf(&co, "v %d %d %d\n", v, r.Intn(1000000)-500000, r.Intn(1000000))
+		}
+		for a := 0; a < 100; a++ {
+			fmt.Fprintf(&gr, "a %d %d %d\n", 1+r.Intn(30), 1+r.Intn(30), r.Intn(100))
+		}
+
+		g, err := Read(strings.NewReader(gr.String()), strings.NewReader(co.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.From, g.To = 1+r.Intn(30), 1+r.Intn(30)
+
+		violations, err := astar.Verify[int](g, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(violations) > 0 {
+			t.Errorf("#%d: %v", i, violations[0])
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		gr, co string
+	}{
+		{"header", "p max 2 1\na 1 2 1\n", ""},
+		{"arc count", "p sp 2 2\na 1 2 1\n", ""},
+		{"arc node", "p sp 2 1\na 1 3 1\n", ""},
+		{"arc cost", "p sp 2 1\na 1 2 -1\n", ""},
+		{"line type", "p sp 2 1\ne 1 2\n", ""},
+		{"coordinate header", "p sp 2 1\na 1 2 1\n", "p aux sp co 3\nv 1 0 0\nv 2 0 0\n"},
+		{"coordinate count", "p sp 2 1\na 1 2 1\n", "p aux sp co 2\nv 1 0 0\n"},
+		{"coordinate", "p sp 2 1\na 1 2 1\n", "p aux sp co 2\nv 1 0 0\nv 2 x 0\n"},
+	} {
+		var err error
+		if tt.co == "" {
+			_, err = Read(strings.NewReader(tt.gr), nil)
+		} else {
+			_, err = Read(strings.NewReader(tt.gr), strings.NewReader(tt.co))
+		}
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
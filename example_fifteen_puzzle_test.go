@@ -0,0 +1,99 @@
+// The 15-puzzle.
+//
+// Slide the tiles into order, moving one tile at a time into the blank
+// space next to it.
+//
+// Use A* Search with an additive pattern database heuristic to solve it
+// in the fewest moves. The tiles are split into four groups, and the moves
+// needed to put each group in place are looked up in precomputed tables
+// and added up.
+//
+//	+----+----+----+----+
+//	|  1 |  2 |  3 |  4 |
+//	+----+----+----+----+
+//	|  5 |  6 |  7 |  8 |
+//	+----+----+----+----+
+//	|  9 | 10 | 11 | 12 |
+//	+----+----+----+----+
+//	| 13 | 14 | 15 |    |
+//	+----+----+----+----+
+package astar_test
+
+import (
+	"fmt"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/pdb"
+)
+
+// Tiles by position, row by row, with 0 for the blank.
+type Board [16]byte
+
+type Fifteen struct {
+	start, curr Board
+	puzzle      pdb.Puzzle
+	estimate    func([]byte) float64
+}
+
+func (f Fifteen) Start() interface{} { return f.start }
+func (f Fifteen) Finish() bool       { return string(f.curr[:]) == string(f.puzzle.Goal) }
+
+func (f *Fifteen) Move(x interface{})            { f.curr = x.(Board) }
+func (f Fifteen) Cost(x interface{}) float64     { return 1 }
+func (f Fifteen) Estimate(x interface{}) float64 { b := x.(Board); return f.estimate(b[:]) }
+func (f Fifteen) Successors() []interface{} {
+	succ := []interface{}{}
+
+	blank := 0
+	for f.curr[blank] != 0 {
+		blank++
+	}
+	for _, pos := range f.puzzle.Adjacent[blank] {
+		next := f.curr
+		next[blank], next[pos] = next[pos], next[blank]
+		succ = append(succ, next)
+	}
+	return succ
+}
+
+func ExampleSearch_fifteenPuzzle() {
+	puzzle := pdb.SlidingTile(4, 4)
+
+	var dbs []*pdb.Database
+	for _, tiles := range [][]byte{
+		{1, 2, 5, 6},
+		{3, 4, 7, 8},
+		{9, 10, 13, 14},
+		{11, 12, 15},
+	} {
+		d, err := pdb.Build(puzzle, tiles, true)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		dbs = append(dbs, d)
+	}
+	estimate, err := pdb.Sum(dbs...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	path, _, err := astar.Search(&Fifteen{
+		start: Board{
+			9, 14, 1, 4,
+			6, 3, 7, 0,
+			10, 13, 12, 8,
+			11, 5, 15, 2,
+		},
+		puzzle:   puzzle,
+		estimate: estimate,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(len(path)-1, "moves")
+	// Output:
+	// 40 moves
+}
@@ -0,0 +1,365 @@
+package astar
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Frontier is a priority queue implementation holding the states
+// waiting to be explored.
+type Frontier int
+
+const (
+	// BinaryHeap is a container/heap based priority queue (default).
+	BinaryHeap Frontier = iota
+
+	// PairingHeap lowers path costs of queued states in O(1) amortized
+	// time instead of O(log n) of BinaryHeap, which may pay off on graphs
+	// where path costs get improved often.
+	PairingHeap
+
+	// BucketQueue keeps a list of states for each Cost() + Estimate() value.
+	// It is the fastest one when costs and estimates are small non-negative
+	// integers. The values of the states queued at once must be within
+	// about a million (2^20) of each other, or the search fails with an error.
+	// States with equal values are explored in LIFO order and TieBreaking()
+	// is ignored.
+	BucketQueue
+
+	// RadixHeap is a monotone priority queue for non-negative integer
+	// costs and estimates of any range. Estimate() must be consistent,
+	// so that Cost() + Estimate() never decreases along the path.
+	// TieBreaking() is ignored.
+	RadixHeap
+)
+
+// WithFrontier sets a priority queue implementation.
+func WithFrontier(f Frontier) Option { return func(c *config) { c.frontier = f } }
+
+// frontier is a priority queue of states ordered by Cost() + Estimate().
+type frontier[S comparable] interface {
+	Len() int
+
+	// insert puts a state on the queue.
+	insert(*state[S]) error
+
+	// popMin takes a state with the minimum value off the queue.
+	popMin() *state[S]
+
+	// decrease restores the order after a queued state's value decreased.
+	decrease(*state[S]) error
+}
+
+func newFrontier[S comparable](c *config, tie func(a, b *state[S]) bool) (frontier[S], error) {
+	switch c.frontier {
+	case BinaryHeap:
+		return &states[S]{items: make([]*state[S], 0, c.sizeHint), tie: tie}, nil
+	case PairingHeap:
+		return &pairingHeap[S]{tie: tie}, nil
+	case BucketQueue:
+		return &bucketQueue[S]{}, nil
+	case RadixHeap:
+		return &radixHeap[S]{}, nil
+	}
+	return nil, fmt.Errorf("astar: unknown frontier %d", c.frontier)
+}
+
+// before reports whether a should be explored before b.
+func before[S comparable](a, b *state[S], tie func(a, b *state[S]) bool) bool {
+	if fa, fb := a.cost+a.estimate, b.cost+b.estimate; fa != fb || tie == nil {
+		return fa < fb
+	}
+	return tie(a, b)
+}
+
+// intKey returns Cost() + Estimate() of s as an integer key
+// for BucketQueue and RadixHeap.
+func intKey[S comparable](s *state[S]) (uint64, error) {
+	f := s.cost + s.estimate
+	if f < 0 || f != math.Trunc(f) || f >= 1<<63 {
+		return 0, fmt.Errorf("astar: Cost() + Estimate() of %v is %v, not a non-negative integer", s.state, f)
+	}
+	return uint64(f), nil
+}
+
+func (pq *states[S]) insert(s *state[S]) error { heap.Push(pq, s); return nil }
+func (pq *states[S]) popMin() *state[S]        { return heap.Pop(pq).(*state[S]) }
+func (pq *states[S]) decrease(s *state[S]) error {
+	heap.Fix(pq, s.index)
+	return nil
+}
+
+// pairingHeap is a pairing heap with two-pass merging.
+type pairingHeap[S comparable] struct {
+	root *pairingNode[S]
+	n    int
+	tie  func(a, b *state[S]) bool
+
+	// Nodes of queued states by state index, and indices of unused nodes.
+	nodes []*pairingNode[S]
+	free  []int
+
+	// Buffer for merging pairs.
+	pairs []*pairingNode[S]
+}
+
+type pairingNode[S comparable] struct {
+	state *state[S]
+	child *pairingNode[S]
+	next  *pairingNode[S]
+
+	// Previous sibling, or parent for the first child.
+	prev *pairingNode[S]
+}
+
+func (h *pairingHeap[S]) Len() int { return h.n }
+
+func (h *pairingHeap[S]) insert(s *state[S]) error {
+	node := &pairingNode[S]{state: s}
+	if n := len(h.free); n > 0 {
+		s.index, h.free = h.free[n-1], h.free[:n-1]
+		h.nodes[s.index] = node
+	} else {
+		s.index = len(h.nodes)
+		h.nodes = append(h.nodes, node)
+	}
+	h.root = h.meld(h.root, node)
+	h.n++
+	return nil
+}
+
+func (h *pairingHeap[S]) popMin() *state[S] {
+	root := h.root
+	h.root = h.mergePairs(root.child)
+	h.nodes[root.state.index] = nil
+	h.free = append(h.free, root.state.index)
+	h.n--
+	return root.state
+}
+
+func (h *pairingHeap[S]) decrease(s *state[S]) error {
+	node := h.nodes[s.index]
+	if node == h.root {
+		return nil
+	}
+
+	// Cut the node off its parent and siblings.
+	if node.prev.child == node {
+		node.prev.child = node.next
+	} else {
+		node.prev.next = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	}
+	node.next, node.prev = nil, nil
+
+	h.root = h.meld(h.root, node)
+	return nil
+}
+
+// meld merges two heaps making the root with a larger value
+// the first child of the other one.
+func (h *pairingHeap[S]) meld(a, b *pairingNode[S]) *pairingNode[S] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if before(b.state, a.state, h.tie) {
+		a, b = b, a
+	}
+	b.prev, b.next = a, a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	return a
+}
+
+// mergePairs melds siblings pairwise from left to right,
+// then the pairs from right to left.
+func (h *pairingHeap[S]) mergePairs(first *pairingNode[S]) *pairingNode[S] {
+	h.pairs = h.pairs[:0]
+	for first != nil {
+		a, b := first, first.next
+		if b == nil {
+			first = nil
+		} else {
+			first = b.next
+			b.prev, b.next = nil, nil
+		}
+		a.prev, a.next = nil, nil
+		h.pairs = append(h.pairs, h.meld(a, b))
+	}
+
+	var root *pairingNode[S]
+	for i := len(h.pairs) - 1; i >= 0; i-- {
+		root = h.meld(h.pairs[i], root)
+	}
+	return root
+}
+
+// maxBucketSpan is the largest difference between the keys queued
+// in a BucketQueue at once.
+const maxBucketSpan = 1 << 20
+
+// bucketQueue is a circular array of state lists: a key goes to the bucket
+// key mod len(buckets), which is a power of two larger than the difference
+// between the keys queued (Dial's algorithm).
+type bucketQueue[S comparable] struct {
+	buckets [][]*state[S]
+	n       int
+
+	// Queued keys are between min and max.
+	min, max uint64
+}
+
+func (q *bucketQueue[S]) Len() int { return q.n }
+
+func (q *bucketQueue[S]) insert(s *state[S]) error {
+	key, err := intKey(s)
+	if err != nil {
+		return err
+	}
+
+	min, max := key, key
+	if q.n > 0 {
+		if q.min < min {
+			min = q.min
+		}
+		if q.max > max {
+			max = q.max
+		}
+	}
+	if max-min >= maxBucketSpan {
+		return fmt.Errorf("astar: Cost() + Estimate() of %v is %d, more than %d away from the queued ones; use RadixHeap or BinaryHeap", s.state, key, maxBucketSpan-1)
+	}
+	if max-min >= uint64(len(q.buckets)) {
+		q.grow(max - min + 1)
+	}
+	q.min, q.max = min, max
+
+	q.put(s, key)
+	q.n++
+	return nil
+}
+
+func (q *bucketQueue[S]) put(s *state[S], key uint64) {
+	i := int(key & uint64(len(q.buckets)-1))
+	s.bucket, s.index = i, len(q.buckets[i])
+	q.buckets[i] = append(q.buckets[i], s)
+}
+
+// grow makes room for a span of keys, redistributing the queued states.
+func (q *bucketQueue[S]) grow(span uint64) {
+	n := 1 << bits.Len64(span-1)
+	if n < 64 {
+		n = 64
+	}
+	old := q.buckets
+	q.buckets = make([][]*state[S], n)
+	for _, bucket := range old {
+		for _, s := range bucket {
+			// Queued keys have been checked on insert.
+			key, _ := intKey(s)
+			q.put(s, key)
+		}
+	}
+}
+
+func (q *bucketQueue[S]) popMin() *state[S] {
+	mask := uint64(len(q.buckets) - 1)
+	for len(q.buckets[q.min&mask]) == 0 {
+		q.min++
+	}
+	i := q.min & mask
+	bucket := q.buckets[i]
+	s := bucket[len(bucket)-1]
+	q.buckets[i] = bucket[:len(bucket)-1]
+	q.n--
+	return s
+}
+
+func (q *bucketQueue[S]) decrease(s *state[S]) error {
+	bucket := q.buckets[s.bucket]
+	last := bucket[len(bucket)-1]
+	bucket[s.index], last.index = last, s.index
+	q.buckets[s.bucket] = bucket[:len(bucket)-1]
+	q.n--
+	return q.insert(s)
+}
+
+// radixHeap is a monotone priority queue: bucket i holds keys which
+// differ from the last popped key in the bit i-1 and none of the higher bits.
+type radixHeap[S comparable] struct {
+	buckets [65][]*state[S]
+	keys    [65][]uint64
+	last    uint64
+	n       int
+}
+
+func (h *radixHeap[S]) Len() int { return h.n }
+
+func (h *radixHeap[S]) insert(s *state[S]) error {
+	key, err := intKey(s)
+	if err != nil {
+		return err
+	}
+	if key < h.last {
+		return fmt.Errorf("astar: Cost() + Estimate() of %v is %d, less than %d explored already; Estimate() is not consistent", s.state, key, h.last)
+	}
+
+	h.put(s, key)
+	h.n++
+	return nil
+}
+
+func (h *radixHeap[S]) put(s *state[S], key uint64) {
+	i := bits.Len64(key ^ h.last)
+	s.bucket, s.index = i, len(h.buckets[i])
+	h.buckets[i] = append(h.buckets[i], s)
+	h.keys[i] = append(h.keys[i], key)
+}
+
+func (h *radixHeap[S]) popMin() *state[S] {
+	if len(h.buckets[0]) == 0 {
+		// Move the minimum key up to the last one and redistribute
+		// the first non-empty bucket among the lower ones.
+		i := 1
+		for len(h.buckets[i]) == 0 {
+			i++
+		}
+		h.last = h.keys[i][0]
+		for _, key := range h.keys[i] {
+			if key < h.last {
+				h.last = key
+			}
+		}
+
+		buckets, keys := h.buckets[i], h.keys[i]
+		h.buckets[i], h.keys[i] = buckets[:0], keys[:0]
+		for j, s := range buckets {
+			h.put(s, keys[j])
+		}
+	}
+
+	n := len(h.buckets[0]) - 1
+	s := h.buckets[0][n]
+	h.buckets[0], h.keys[0] = h.buckets[0][:n], h.keys[0][:n]
+	h.n--
+	return s
+}
+
+func (h *radixHeap[S]) decrease(s *state[S]) error {
+	i, bucket := s.bucket, h.buckets[s.bucket]
+	n := len(bucket) - 1
+	bucket[s.index], bucket[n].index = bucket[n], s.index
+	h.keys[i][s.index] = h.keys[i][n]
+	h.buckets[i], h.keys[i] = bucket[:n], h.keys[i][:n]
+	h.n--
+	return h.insert(s)
+}
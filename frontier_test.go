@@ -0,0 +1,172 @@
+package astar_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	. "github.com/pietv/astar"
+)
+
+var frontiers = []struct {
+	name     string
+	frontier Frontier
+}{
+	{"binary heap", BinaryHeap},
+	{"pairing heap", PairingHeap},
+	{"bucket queue", BucketQueue},
+	{"radix heap", RadixHeap},
+}
+
+// wallGrid is a square grid maze with randomly placed walls,
+// from the top left to the bottom right corner.
+type wallGrid struct {
+	n     int
+	walls []bool
+}
+
+func newWallGrid(n int, density float64, seed int64) wallGrid {
+	r := rand.New(rand.NewSource(seed))
+	g := wallGrid{n, make([]bool, n*n)}
+	for i := range g.walls {
+		g.walls[i] = r.Float64() < density
+	}
+	g.walls[0], g.walls[n*n-1] = false, false
+	return g
+}
+
+func (g wallGrid) Start() [2]int            { return [2]int{0, 0} }
+func (g wallGrid) IsGoal(state [2]int) bool { return state == [2]int{g.n - 1, g.n - 1} }
+func (g wallGrid) Heuristic(state [2]int) float64 {
+	return float64(2*(g.n-1) - state[0] - state[1])
+}
+func (g wallGrid) Neighbors(state [2]int) []Edge[[2]int] {
+	edges := make([]Edge[[2]int], 0, 4)
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		i, j := state[0]+d[0], state[1]+d[1]
+		if i >= 0 && j >= 0 && i < g.n && j < g.n && !g.walls[i*g.n+j] {
+			edges = append(edges, Edge[[2]int]{[2]int{i, j}, 1})
+		}
+	}
+	return edges
+}
+
+// randomGraph is a directed graph with random integer edge costs
+// and no estimate, from vertex 0 to vertex n-1.
+type randomGraph [][]Edge[int]
+
+func newRandomGraph(n, degree int, seed int64) randomGraph {
+	r := rand.New(rand.NewSource(seed))
+	g := make(randomGraph, n)
+	for v := range g {
+		for i := 0; i < degree; i++ {
+			g[v] = append(g[v], Edge[int]{r.Intn(n), float64(1 + r.Intn(100))})
+		}
+	}
+	return g
+}
+
+func (g randomGraph) Start() int                  { return 0 }
+func (g randomGraph) IsGoal(v int) bool           { return v == len(g)-1 }
+func (g randomGraph) Heuristic(v int) float64     { return 0 }
+func (g randomGraph) Neighbors(v int) []Edge[int] { return g[v] }
+
+func TestFrontiers(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		maze, graph := newWallGrid(30, 0.3, seed), newRandomGraph(500, 3, seed)
+
+		mazeRes, mazeErr := SearchResult[[2]int](context.Background(), maze)
+		graphRes, graphErr := SearchResult[int](context.Background(), graph)
+
+		for _, f := range frontiers {
+			res, err := SearchResult[[2]int](context.Background(), maze, WithFrontier(f.frontier))
+			if err != mazeErr || res.Cost != mazeRes.Cost {
+				t.Errorf("%s, maze #%d: got cost %v, error %v, want %v, %v", f.name, seed, res.Cost, err, mazeRes.Cost, mazeErr)
+			}
+
+			res2, err := SearchResult[int](context.Background(), graph, WithFrontier(f.frontier))
+			if err != graphErr || res2.Cost != graphRes.Cost {
+				t.Errorf("%s, graph #%d: got cost %v, error %v, want %v, %v", f.name, seed, res2.Cost, err, graphRes.Cost, graphErr)
+			}
+		}
+	}
+}
+
+func TestIntegerFrontiers(t *testing.T) {
+	g := statelessGraph{map[string]map[string]float64{
+		"A": {"B": 0.5},
+	}, "A", "B"}
+
+	for _, frontier := range []Frontier{BucketQueue, RadixHeap} {
+		if _, _, err := SearchProblem[string](context.Background(), g, WithFrontier(frontier)); err == nil {
+			t.Errorf("frontier %d: got no error for a fractional cost", frontier)
+		}
+	}
+
+	// Large costs are fine as long as the queued values are close.
+	chain := statelessGraph{map[string]map[string]float64{
+		"A": {"B": 1e12},
+		"B": {"C": 1e12},
+	}, "A", "C"}
+	if res, err := SearchResult[string](context.Background(), chain, WithFrontier(BucketQueue)); err != nil || res.Cost != 2e12 {
+		t.Errorf("bucket queue, large costs: got cost %v, error %v, want 2e12", res.Cost, err)
+	}
+	spread := statelessGraph{map[string]map[string]float64{
+		"A": {"B": 1, "C": 1e12},
+		"B": {"D": 1},
+	}, "A", "C"}
+	if _, err := SearchResult[string](context.Background(), spread, WithFrontier(BucketQueue)); err == nil {
+		t.Errorf("bucket queue: got no error for values 1e12 apart")
+	}
+	if res, err := SearchResult[string](context.Background(), spread, WithFrontier(RadixHeap)); err != nil || res.Cost != 1e12 {
+		t.Errorf("radix heap, large costs: got cost %v, error %v, want 1e12", res.Cost, err)
+	}
+
+	// Inconsistent estimates need reopening, which RadixHeap can't do.
+	h := inconsistentGraph{statelessGraph{map[string]map[string]float64{
+		"S": {"A": 1, "B": 3},
+		"A": {"B": 1},
+		"B": {"G": 3},
+	}, "S", "G"}}
+	if res, err := SearchResult[string](context.Background(), h, Reopen(), WithFrontier(BucketQueue)); err != nil || res.Cost != 5 {
+		t.Errorf("bucket queue: got cost %v, error %v, want 5", res.Cost, err)
+	}
+	if _, err := SearchResult[string](context.Background(), h, Reopen(), WithFrontier(RadixHeap)); err == nil {
+		t.Errorf("radix heap: got no error for an inconsistent estimate")
+	}
+}
+
+func BenchmarkFrontierMaze(b *testing.B) {
+	maze := newWallGrid(300, 0.3, 1)
+	for _, f := range frontiers {
+		b.Run(f.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SearchResult[[2]int](context.Background(), maze, WithFrontier(f.frontier), LastSteps(0))
+			}
+		})
+	}
+}
+
+func BenchmarkFrontierGraph(b *testing.B) {
+	graph := newRandomGraph(100000, 4, 1)
+	for _, f := range frontiers {
+		b.Run(f.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SearchResult[int](context.Background(), graph, WithFrontier(f.frontier), LastSteps(0))
+			}
+		})
+	}
+}
+
+func ExampleWithFrontier() {
+	// A 3×3 maze:
+	//   S**
+	//   ..*
+	//   *.F
+	maze := wallGrid{3, []bool{false, true, true, false, false, true, true, false, false}}
+
+	res, _ := SearchResult[[2]int](context.Background(), maze, WithFrontier(BucketQueue))
+	fmt.Println(res.Path, res.Cost)
+	// Output: [[0 0] [1 0] [1 1] [2 1] [2 2]] 4
+}
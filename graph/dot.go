@@ -0,0 +1,431 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pietv/astar/heuristics"
+)
+
+// LoadDOT reads a graph in the Graphviz DOT language. Graphs are directed
+// if declared with “digraph”. Edge costs are taken from the “weight”,
+// “cost” or “label” attributes, whichever is a number first, and default
+// to 1. Node coordinates are taken from the “pos” attribute, as in
+// pos="1,2" or pos="1,2!".
+//
+// Subgraphs are flattened and default attribute statements, such as
+// “edge [weight=2]”, are ignored. Edges to subgraphs and HTML strings
+// are not supported.
+func LoadDOT(r io.Reader) (*Graph[string], error) {
+	p := &dotParser{s: bufio.NewReader(r), line: 1}
+	g, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("graph: line %d: %w", p.line, err)
+	}
+	return g, nil
+}
+
+type dotParser struct {
+	s    *bufio.Reader
+	line int
+
+	// Lookahead token.
+	tok    string
+	quoted bool
+	peeked bool
+}
+
+// next returns the next token: an ID, a quoted string, or punctuation.
+// It returns "" at the end of input.
+func (p *dotParser) next() (string, error) {
+	if p.peeked {
+		p.peeked = false
+		return p.tok, nil
+	}
+
+	p.quoted = false
+	for {
+		c, err := p.read()
+		if err == io.EOF {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case c == '\n':
+			p.line++
+		case unicode.IsSpace(c):
+		case c == '#':
+			p.skipLine()
+		case c == '/':
+			switch n, _ := p.read(); n {
+			case '/':
+				p.skipLine()
+			case '*':
+				if err := p.skipComment(); err != nil {
+					return "", err
+				}
+			default:
+				return "", fmt.Errorf("unexpected %q", "/"+string(n))
+			}
+		case c == '"':
+			p.quoted = true
+			return p.quote()
+		case c == '-':
+			switch n, _ := p.read(); n {
+			case '>':
+				return "->", nil
+			case '-':
+				return "--", nil
+			default:
+				p.s.UnreadRune()
+				return p.id(c)
+			}
+		case strings.ContainsRune("{}[]=;,:", c):
+			return string(c), nil
+		case c == '<':
+			return "", fmt.Errorf("HTML strings are not supported")
+		default:
+			return p.id(c)
+		}
+	}
+}
+
+func (p *dotParser) peek() (string, error) {
+	tok, err := p.next()
+	p.tok, p.peeked = tok, true
+	return tok, err
+}
+
+func (p *dotParser) read() (rune, error) {
+	c, _, err := p.s.ReadRune()
+	return c, err
+}
+
+func (p *dotParser) skipLine() {
+	for {
+		c, err := p.read()
+		if err != nil {
+			return
+		}
+		if c == '\n' {
+			p.line++
+			return
+		}
+	}
+}
+
+func (p *dotParser) skipComment() error {
+	for star := false; ; {
+		c, err := p.read()
+		if err != nil {
+			return fmt.Errorf("unterminated comment")
+		}
+		if c == '\n' {
+			p.line++
+		}
+		if star && c == '/' {
+			return nil
+		}
+		star = c == '*'
+	}
+}
+
+func (p *dotParser) quote() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := p.read()
+		if err != nil {
+			return "", fmt.Errorf("unterminated string")
+		}
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			n, err := p.read()
+			if err != nil {
+				return "", fmt.Errorf("unterminated string")
+			}
+			switch n {
+			case '"':
+				b.WriteRune('"')
+			case '\n':
+				// Line continuation.
+				p.line++
+			default:
+				b.WriteRune('\\')
+				b.WriteRune(n)
+			}
+		case '\n':
+			p.line++
+			b.WriteRune(c)
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+func (p *dotParser) id(first rune) (string, error) {
+	b := []rune{first}
+	for {
+		c, err := p.read()
+		if err != nil {
+			break
+		}
+		if !(c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			p.s.UnreadRune()
+			break
+		}
+		b = append(b, c)
+	}
+	if first != '_' && first != '.' && first != '-' && !unicode.IsLetter(first) && !unicode.IsDigit(first) {
+		return "", fmt.Errorf("unexpected %q", string(b))
+	}
+	return string(b), nil
+}
+
+func (p *dotParser) expect(tok string) error {
+	got, err := p.next()
+	if err != nil {
+		return err
+	}
+	if got != tok || p.quoted {
+		return fmt.Errorf("expected %q, got %q", tok, got)
+	}
+	return nil
+}
+
+// keyword tells whether the last token is a case-insensitive keyword.
+func (p *dotParser) keyword(tok, keyword string) bool {
+	return !p.quoted && strings.EqualFold(tok, keyword)
+}
+
+func (p *dotParser) parse() (*Graph[string], error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if p.keyword(tok, "strict") {
+		if tok, err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	var g *Graph[string]
+	switch {
+	case p.keyword(tok, "graph"):
+		g = New[string](false)
+	case p.keyword(tok, "digraph"):
+		g = New[string](true)
+	default:
+		return nil, fmt.Errorf("expected graph or digraph, got %q", tok)
+	}
+
+	// Optional graph name.
+	if tok, err = p.peek(); err != nil {
+		return nil, err
+	}
+	if tok != "{" || p.quoted {
+		p.next()
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := p.next()
+		switch {
+		case err != nil:
+			return nil, err
+		case tok == "" && !p.quoted:
+			return nil, fmt.Errorf("unexpected end of input")
+		case p.quoted:
+			if err := p.statement(g, tok); err != nil {
+				return nil, err
+			}
+		case tok == "{":
+			depth++
+		case tok == "}":
+			depth--
+		case tok == ";" || tok == ",":
+		case p.keyword(tok, "subgraph"):
+			// Flattened; skip the name.
+			if tok, err = p.peek(); err != nil {
+				return nil, err
+			}
+			if tok != "{" || p.quoted {
+				p.next()
+			}
+		case p.keyword(tok, "graph") || p.keyword(tok, "node") || p.keyword(tok, "edge"):
+			if err := p.attributes(nil); err != nil {
+				return nil, err
+			}
+		case strings.ContainsAny(tok, "[]=:") || tok == "->" || tok == "--":
+			return nil, fmt.Errorf("unexpected %q", tok)
+		default:
+			if err := p.statement(g, tok); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g, nil
+}
+
+// statement parses a node statement, an edge statement, or
+// a graph attribute assignment starting with the given ID.
+func (p *dotParser) statement(g *Graph[string], id string) error {
+	nodes := []string{id}
+	if err := p.port(); err != nil {
+		return err
+	}
+
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if p.quoted || tok != "->" && tok != "--" {
+			break
+		}
+		if (tok == "->") != g.Directed() {
+			return fmt.Errorf("edge operator %q does not match the graph type", tok)
+		}
+		p.next()
+
+		to, err := p.next()
+		if err != nil {
+			return err
+		}
+		if !p.quoted && (to == "" || strings.ContainsAny(to, "{}[]=;,:")) {
+			return fmt.Errorf("expected a node ID, got %q", to)
+		}
+		nodes = append(nodes, to)
+		if err := p.port(); err != nil {
+			return err
+		}
+	}
+
+	tok, err := p.peek()
+	if err != nil {
+		return err
+	}
+	if tok == "=" && !p.quoted && len(nodes) == 1 {
+		// Graph attribute: ID = ID.
+		p.next()
+		_, err := p.next()
+		return err
+	}
+
+	attrs := map[string]string{}
+	if err := p.attributes(attrs); err != nil {
+		return err
+	}
+
+	if len(nodes) == 1 {
+		g.AddNode(id)
+		if pos, ok := attrs["pos"]; ok {
+			c, err := parsePos(pos)
+			if err != nil {
+				return fmt.Errorf("node %q: %w", id, err)
+			}
+			g.SetCoord(id, c)
+		}
+		return nil
+	}
+
+	cost := 1.0
+	for _, key := range []string{"weight", "cost", "label"} {
+		if v, err := strconv.ParseFloat(attrs[key], 64); err == nil {
+			cost = v
+			break
+		}
+	}
+	if cost < 0 {
+		return fmt.Errorf("edge %q: negative cost %v", nodes, cost)
+	}
+	for i := 1; i < len(nodes); i++ {
+		g.AddEdge(nodes[i-1], nodes[i], cost)
+	}
+	return nil
+}
+
+// port skips an optional node port, as in “a:n” or “a:p1:ne”.
+func (p *dotParser) port() error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok != ":" || p.quoted {
+			return nil
+		}
+		p.next()
+		if _, err := p.next(); err != nil {
+			return err
+		}
+	}
+}
+
+// attributes parses optional attribute lists, as in “[a=1, b=2][c=3]”,
+// into attrs, unless it is nil.
+func (p *dotParser) attributes(attrs map[string]string) error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok != "[" || p.quoted {
+			return nil
+		}
+		p.next()
+
+		for {
+			key, err := p.next()
+			if err != nil {
+				return err
+			}
+			if !p.quoted && (key == ";" || key == ",") {
+				continue
+			}
+			if !p.quoted && key == "]" {
+				break
+			}
+			if key == "" && !p.quoted {
+				return fmt.Errorf("unterminated attribute list")
+			}
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			value, err := p.next()
+			if err != nil {
+				return err
+			}
+			if attrs != nil {
+				attrs[key] = value
+			}
+		}
+	}
+}
+
+// parsePos parses a Graphviz point, “x,y” with an optional “!”.
+func parsePos(pos string) (heuristics.Point, error) {
+	xy := strings.Split(strings.TrimSuffix(strings.TrimSpace(pos), "!"), ",")
+	if len(xy) < 2 {
+		return heuristics.Point{}, fmt.Errorf("bad pos %q", pos)
+	}
+	x, err := strconv.ParseFloat(strings.TrimSpace(xy[0]), 64)
+	if err != nil {
+		return heuristics.Point{}, fmt.Errorf("bad pos %q", pos)
+	}
+	y, err := strconv.ParseFloat(strings.TrimSpace(xy[1]), 64)
+	if err != nil {
+		return heuristics.Point{}, fmt.Errorf("bad pos %q", pos)
+	}
+	return heuristics.Point{X: x, Y: y}, nil
+}
@@ -0,0 +1,199 @@
+// Package graph implements a weighted graph for A* search.
+//
+// A Graph is an adjacency list, directed or undirected, with optional node
+// coordinates for the heuristic. It is an astar.Problem searching from
+// the From node to the To node:
+//
+//	g, err := graph.LoadCSV(f, false)
+//	g.From, g.To = "Arad", "Bucharest"
+//	res, err := astar.SearchResult[string](ctx, g)
+//
+// A Graph also has the methods to be searched with astar.SearchBidirectional
+// and astar.Planner.
+package graph
+
+import (
+	"math"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/heuristics"
+)
+
+// Graph is a weighted graph with nodes of type N.
+type Graph[N comparable] struct {
+	// Start and finish nodes.
+	From, To N
+
+	// Distance between node coordinates; heuristics.Euclidean if nil.
+	Metric heuristics.Metric
+
+	// Metric multiplier. Distances are only admissible estimates
+	// if no edge costs less than Scale times the distance between
+	// its nodes; see FitScale.
+	Scale float64
+
+	directed bool
+	nodes    []N
+	index    map[N]int
+	succ     [][]astar.Edge[N]
+	pred     [][]astar.Edge[N]
+	coords   []heuristics.Point
+	located  []bool
+}
+
+// New returns an empty directed or undirected graph.
+func New[N comparable](directed bool) *Graph[N] {
+	return &Graph[N]{
+		Scale:    1,
+		directed: directed,
+		index:    map[N]int{},
+	}
+}
+
+// Directed tells whether edges only go one way.
+func (g *Graph[N]) Directed() bool { return g.directed }
+
+// Len returns the number of nodes.
+func (g *Graph[N]) Len() int { return len(g.nodes) }
+
+// Nodes returns all nodes in the order they were added.
+func (g *Graph[N]) Nodes() []N { return g.nodes }
+
+// Has tells whether n is in the graph.
+func (g *Graph[N]) Has(n N) bool {
+	_, ok := g.index[n]
+	return ok
+}
+
+// AddNode adds n to the graph if it is not there yet.
+func (g *Graph[N]) AddNode(n N) {
+	g.node(n)
+}
+
+func (g *Graph[N]) node(n N) int {
+	i, ok := g.index[n]
+	if !ok {
+		i = len(g.nodes)
+		g.index[n] = i
+		g.nodes = append(g.nodes, n)
+		g.succ = append(g.succ, nil)
+		g.pred = append(g.pred, nil)
+		g.coords = append(g.coords, heuristics.Point{})
+		g.located = append(g.located, false)
+	}
+	return i
+}
+
+// AddEdge adds an edge from one node to another, and back if the graph
+// is undirected, adding the nodes as needed. If the edge exists,
+// its cost is replaced.
+func (g *Graph[N]) AddEdge(from, to N, cost float64) {
+	f, t := g.node(from), g.node(to)
+	g.succ[f] = set(g.succ[f], to, cost)
+	g.pred[t] = set(g.pred[t], from, cost)
+	if !g.directed {
+		g.succ[t] = set(g.succ[t], from, cost)
+		g.pred[f] = set(g.pred[f], to, cost)
+	}
+}
+
+func set[N comparable](edges []astar.Edge[N], to N, cost float64) []astar.Edge[N] {
+	for i := range edges {
+		if edges[i].To == to {
+			edges[i].Cost = cost
+			return edges
+		}
+	}
+	return append(edges, astar.Edge[N]{To: to, Cost: cost})
+}
+
+// SetCoord sets the location of n, adding it as needed.
+func (g *Graph[N]) SetCoord(n N, c heuristics.Point) {
+	i := g.node(n)
+	g.coords[i], g.located[i] = c, true
+}
+
+// Coord returns the location of n, if it has one.
+func (g *Graph[N]) Coord(n N) (heuristics.Point, bool) {
+	i, ok := g.index[n]
+	if !ok || !g.located[i] {
+		return heuristics.Point{}, false
+	}
+	return g.coords[i], true
+}
+
+// FitScale sets Scale to the largest value keeping the distance estimates
+// admissible, that is the lowest ratio of an edge cost to the distance
+// between its nodes, and returns it. Edges between nodes without
+// coordinates are not considered.
+func (g *Graph[N]) FitScale() float64 {
+	scale := math.Inf(1)
+	for i, edges := range g.succ {
+		for _, e := range edges {
+			j := g.index[e.To]
+			if !g.located[i] || !g.located[j] {
+				continue
+			}
+			if d := g.metric(g.coords[i], g.coords[j]); d > 0 && e.Cost/d < scale {
+				scale = e.Cost / d
+			}
+		}
+	}
+	if math.IsInf(scale, 1) {
+		scale = 1
+	}
+	g.Scale = scale
+	return scale
+}
+
+func (g *Graph[N]) metric(a, b heuristics.Point) float64 {
+	if g.Metric == nil {
+		return heuristics.Euclidean(a, b)
+	}
+	return g.Metric(a, b)
+}
+
+// Start returns g.From.
+func (g *Graph[N]) Start() N { return g.From }
+
+// IsGoal tells whether n is g.To.
+func (g *Graph[N]) IsGoal(n N) bool { return n == g.To }
+
+// Neighbors returns the edges leaving n.
+func (g *Graph[N]) Neighbors(n N) []astar.Edge[N] {
+	if i, ok := g.index[n]; ok {
+		return g.succ[i]
+	}
+	return nil
+}
+
+// Goal returns g.To.
+func (g *Graph[N]) Goal() N { return g.To }
+
+// Predecessors returns the edges entering n, reversed.
+func (g *Graph[N]) Predecessors(n N) []astar.Edge[N] {
+	if i, ok := g.index[n]; ok {
+		return g.pred[i]
+	}
+	return nil
+}
+
+// Heuristic estimates the path cost from n to g.To.
+func (g *Graph[N]) Heuristic(n N) float64 { return g.Distance(n, g.To) }
+
+// HeuristicFromStart estimates the path cost from g.From to n.
+func (g *Graph[N]) HeuristicFromStart(n N) float64 { return g.Distance(g.From, n) }
+
+// Distance estimates the path cost between two nodes: the distance between
+// their coordinates times Scale, or 0 if either has no coordinates.
+func (g *Graph[N]) Distance(from, to N) float64 {
+	a, ok := g.Coord(from)
+	if !ok {
+		return 0
+	}
+	b, ok := g.Coord(to)
+	if !ok {
+		return 0
+	}
+	return g.metric(a, b) * g.Scale
+}
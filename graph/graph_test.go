@@ -0,0 +1,203 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/pietv/astar"
+	. "github.com/pietv/astar/graph"
+	"github.com/pietv/astar/heuristics"
+)
+
+// A fragment of the Romanian road map from Stuart Russell and Peter Norvig's
+// “Artificial Intelligence. A Modern Approach”, 3rd ed., 2009, p. 68.
+var romania = `from,to,distance
+Arad,Zerind,75
+Arad,Timișoara,118
+Arad,Sibiu,140
+Zerind,Oradea,71
+Oradea,Sibiu,151
+Timișoara,Lugoj,111
+Lugoj,Mehadia,70
+Mehadia,Drobeta,75
+Drobeta,Craiova,120
+Craiova,Râmnicu Vâlcea,146
+Craiova,Pitești,138
+Sibiu,Făgăraș,99
+Sibiu,Râmnicu Vâlcea,80
+Râmnicu Vâlcea,Pitești,97
+Făgăraș,Bucharest,211
+Pitești,Bucharest,101
+Bucharest,Giurgiu,90
+Bucharest,Urziceni,85
+`
+
+func ExampleLoadCSV() {
+	g, _ := LoadCSV(strings.NewReader(romania), false)
+	g.From, g.To = "Arad", "Bucharest"
+	res, _ := astar.SearchResult[string](context.Background(), g)
+
+	fmt.Println(res.Path, res.Cost)
+	// Output: [Arad Sibiu Râmnicu Vâlcea Pitești Bucharest] 418
+}
+
+func TestEdges(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		g := New[int](directed)
+		g.AddEdge(1, 2, 5)
+		g.AddEdge(2, 3, 1)
+		g.AddEdge(1, 2, 4)
+		g.AddNode(4)
+
+		if g.Len() != 4 || !g.Has(4) || g.Has(5) {
+			t.Errorf("directed %v: got nodes %v", directed, g.Nodes())
+		}
+		if got := g.Neighbors(1); len(got) != 1 || got[0].Cost != 4 {
+			t.Errorf("directed %v: got neighbors %v, expected the replaced cost", directed, got)
+		}
+		if got := len(g.Neighbors(2)); got != map[bool]int{false: 2, true: 1}[directed] {
+			t.Errorf("directed %v: got %d neighbors of 2", directed, got)
+		}
+		if got := g.Predecessors(3); len(got) != 1 || got[0].To != 2 {
+			t.Errorf("directed %v: got predecessors %v", directed, got)
+		}
+
+		g.From, g.To = 3, 1
+		_, err := astar.SearchResult[int](context.Background(), g)
+		if directed && err != astar.ErrNotFound || !directed && err != nil {
+			t.Errorf("directed %v: got %v", directed, err)
+		}
+	}
+}
+
+// Scaled coordinate distances are consistent and agree with the
+// bidirectional search.
+func TestCoords(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		g := New[int](i%2 == 0)
+		for n := 0; n < 50; n++ {
+			g.SetCoord(n, heuristics.Point{X: r.Float64() * 100, Y: r.Float64() * 100})
+		}
+		for e := 0; e < 200; e++ {
+			from, to := r.Intn(50), r.Intn(50)
+			a, _ := g.Coord(from)
+			b, _ := g.Coord(to)
+			g.AddEdge(from, to, heuristics.Euclidean(a, b)*(0.5+r.Float64()))
+		}
+		g.From, g.To = r.Intn(50), r.Intn(50)
+
+		if scale := g.FitScale(); scale < 0.5 || scale > 1.5 {
+			t.Fatalf("#%d: got scale %v", i, scale)
+		}
+		violations, err := astar.Verify[int](g, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(violations) > 0 {
+			t.Fatalf("#%d: %v", i, violations[0])
+		}
+
+		res, err := astar.SearchResult[int](context.Background(), g)
+		bidi, bidiErr := astar.SearchBidirectional[int](context.Background(), g)
+		if (err == nil) != (bidiErr == nil) {
+			t.Fatalf("#%d: forward %v, bidirectional %v", i, err, bidiErr)
+		}
+		if err == nil && math.Abs(res.Cost-bidi.Cost) > 1e-9 {
+			t.Errorf("#%d: forward cost %v, bidirectional %v", i, res.Cost, bidi.Cost)
+		}
+	}
+}
+
+func TestLoaders(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		load func() (*Graph[string], error)
+	}{
+		{"JSON", func() (*Graph[string], error) {
+			return LoadJSON(strings.NewReader(`{
+				"directed": true,
+				"nodes": [{"id": "a", "x": 0, "y": 0}, {"id": "d", "x": 3, "y": 0}, {"id": "e"}],
+				"edges": [
+					{"from": "a", "to": "b", "cost": 1},
+					{"from": "b", "to": "c"},
+					{"from": "c", "to": "d", "cost": 1.5},
+					{"from": "a", "to": "d", "cost": 4}
+				]
+			}`))
+		}},
+		{"CSV", func() (*Graph[string], error) {
+			return LoadCSV(strings.NewReader("# Comment.\na,b,1\nb, c\nc,d,1.5\n\na,d,4\n"), true)
+		}},
+		{"DOT", func() (*Graph[string], error) {
+			return LoadDOT(strings.NewReader(`
+				/* A comment. */
+				strict digraph "G" {
+					rankdir=LR
+					node [shape=circle]
+					a [pos="0,0!"]; "d" [pos="3,0"]
+					e
+					a -> b [weight=1] // Edge.
+					b -> c:n [label="x"]
+					subgraph cluster { c -> d [cost=1.5, color=red] }
+					# Another comment.
+					a -> d [label=4]
+				}`))
+		}},
+	} {
+		g, err := tt.load()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !g.Directed() {
+			t.Errorf("%s: undirected", tt.name)
+		}
+		if c, ok := g.Coord("d"); (tt.name != "CSV") != ok || ok && c != (heuristics.Point{X: 3}) {
+			t.Errorf("%s: got coordinates %v, %v", tt.name, c, ok)
+		}
+
+		g.From, g.To = "a", "d"
+		res, err := astar.SearchResult[string](context.Background(), g)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if fmt.Sprint(res.Path) != "[a b c d]" || res.Cost != 3.5 {
+			t.Errorf("%s: got %v of cost %v", tt.name, res.Path, res.Cost)
+		}
+	}
+
+	g, err := LoadCSV(strings.NewReader("source,target\na,b\n"), true)
+	if err != nil || g.Has("source") || g.Len() != 2 {
+		t.Errorf("CSV header without costs: got %v, %v", g.Nodes(), err)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		load func() (*Graph[string], error)
+	}{
+		{"JSON syntax", func() (*Graph[string], error) { return LoadJSON(strings.NewReader(`{"edges": [}`)) }},
+		{"JSON coordinate", func() (*Graph[string], error) {
+			return LoadJSON(strings.NewReader(`{"nodes": [{"id": "a", "x": 1}]}`))
+		}},
+		{"JSON cost", func() (*Graph[string], error) {
+			return LoadJSON(strings.NewReader(`{"edges": [{"from": "a", "to": "b", "cost": -1}]}`))
+		}},
+		{"CSV fields", func() (*Graph[string], error) { return LoadCSV(strings.NewReader("a,b,1,2\n"), false) }},
+		{"CSV cost", func() (*Graph[string], error) { return LoadCSV(strings.NewReader("a,b,1\nb,c,x\n"), false) }},
+		{"DOT header", func() (*Graph[string], error) { return LoadDOT(strings.NewReader(`tree { a }`)) }},
+		{"DOT operator", func() (*Graph[string], error) { return LoadDOT(strings.NewReader(`graph { a -> b }`)) }},
+		{"DOT unterminated", func() (*Graph[string], error) { return LoadDOT(strings.NewReader(`digraph { a -> b`)) }},
+		{"DOT string", func() (*Graph[string], error) { return LoadDOT(strings.NewReader(`digraph { "a -> b }`)) }},
+		{"DOT pos", func() (*Graph[string], error) { return LoadDOT(strings.NewReader(`digraph { a [pos=1] }`)) }},
+	} {
+		if _, err := tt.load(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pietv/astar/heuristics"
+)
+
+// LoadJSON reads a graph in the following form:
+//
+//	{
+//	  "directed": false,
+//	  "nodes": [{"id": "A", "x": 0, "y": 0}, {"id": "B", "x": 3, "y": 4}],
+//	  "edges": [{"from": "A", "to": "B", "cost": 5}]
+//	}
+//
+// Nodes only need to be listed to give them coordinates; edges without
+// a cost cost 1.
+func LoadJSON(r io.Reader) (*Graph[string], error) {
+	var in struct {
+		Directed bool
+		Nodes    []struct {
+			ID   string
+			X, Y *float64
+		}
+		Edges []struct {
+			From, To string
+			Cost     *float64
+		}
+	}
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("graph: %w", err)
+	}
+
+	g := New[string](in.Directed)
+	for _, n := range in.Nodes {
+		switch {
+		case n.X != nil && n.Y != nil:
+			g.SetCoord(n.ID, heuristics.Point{X: *n.X, Y: *n.Y})
+		case n.X != nil || n.Y != nil:
+			return nil, fmt.Errorf("graph: node %q has only one coordinate", n.ID)
+		default:
+			g.AddNode(n.ID)
+		}
+	}
+	for _, e := range in.Edges {
+		cost := 1.0
+		if e.Cost != nil {
+			cost = *e.Cost
+		}
+		if cost < 0 {
+			return nil, fmt.Errorf("graph: edge %q-%q has negative cost %v", e.From, e.To, cost)
+		}
+		g.AddEdge(e.From, e.To, cost)
+	}
+	return g, nil
+}
+
+// LoadCSV reads an edge list, one “from,to,cost” record per line.
+// The cost is optional and defaults to 1. A first line without a cost,
+// such as “source,target”, or with a cost that is not a number, such as
+// “source,target,weight”, is taken for a header and skipped, so lists
+// of edges without costs need a header line.
+func LoadCSV(r io.Reader, directed bool) (*Graph[string], error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	g := New[string](directed)
+	for i := 0; ; i++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return g, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graph: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("graph: line %d: expected 2 or 3 fields, got %d", line, len(record))
+		}
+
+		if len(record) == 2 && i == 0 {
+			// Header.
+			continue
+		}
+
+		cost := 1.0
+		if len(record) == 3 {
+			cost, err = strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+			if err != nil && i == 0 {
+				// Header.
+				continue
+			}
+			if err != nil || cost < 0 {
+				return nil, fmt.Errorf("graph: line %d: bad cost %q", line, record[2])
+			}
+		}
+		g.AddEdge(strings.TrimSpace(record[0]), strings.TrimSpace(record[1]), cost)
+	}
+}
@@ -0,0 +1,275 @@
+// Package grid implements a rectangular grid map for A* search.
+//
+// A Grid is an astar.Problem[Point]: the search moves between adjacent cells,
+// 4-connected (north, south, west, east) or 8-connected (diagonals as well),
+// from the From location to the To location. Moving into a cell costs
+// the cell's terrain cost multiplied by the length of the move: 1 for
+// straight moves and √2 for diagonal ones. Cells with infinite cost are walls.
+//
+// A Grid also has the methods to be searched with astar.SearchBidirectional
+// and astar.Planner, and to be passed to jps.Search. Jump Point Search only
+// works on grids where all cells that aren't walls cost 1, and it fails with
+// jps.ErrNonUniform on the others.
+//
+//	g := grid.Parse([]string{
+//		`S  *   `,
+//		` * * * `,
+//		`   *  F`,
+//	})
+//	g.Connectivity = grid.Eight
+//	res, err := astar.SearchResult[grid.Point](context.Background(), g)
+package grid
+
+import (
+	"math"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/heuristics"
+)
+
+// Point is a cell location.
+type Point struct {
+	Row, Col int
+}
+
+// Connectivity tells which cells are adjacent.
+type Connectivity int
+
+const (
+	// Four: north, south, west and east.
+	Four Connectivity = 4
+
+	// Eight: diagonal cells as well.
+	Eight Connectivity = 8
+)
+
+// Corners tells when diagonal moves next to walls are allowed
+// on 8-connected grids.
+type Corners int
+
+const (
+	// NoCorners only allows diagonal moves if both cells next to them are
+	// passable, so that paths never cut wall corners (default).
+	NoCorners Corners = iota
+
+	// OneCorner allows diagonal moves with at most one wall next to them.
+	OneCorner
+
+	// AnyCorners allows diagonal moves between any passable cells,
+	// squeezing between walls touching at corners.
+	AnyCorners
+)
+
+// Location returns the location of p for heuristics metrics:
+// its column as X and its row as Y.
+func Location(p Point) heuristics.Point {
+	return heuristics.Point{X: float64(p.Col), Y: float64(p.Row)}
+}
+
+// Grid is a rectangular grid map with per-cell terrain costs.
+type Grid struct {
+	// Start and finish locations.
+	From, To Point
+
+	// Four (default) or Eight.
+	Connectivity Connectivity
+
+	// Diagonal moves next to walls on 8-connected grids.
+	Corners Corners
+
+	// Distance estimate between cell Locations; heuristics.Manhattan
+	// on 4-connected grids and heuristics.Octile on 8-connected ones
+	// if nil. It is multiplied by the cheapest terrain cost of the grid
+	// to keep it admissible.
+	Metric heuristics.Metric
+
+	// Optional extra passability predicate. Cells it returns false for
+	// are walls regardless of their cost.
+	CanEnter func(Point) bool
+
+	rows, cols int
+	costs      []float64
+
+	// The cheapest terrain cost so far.
+	minCost float64
+}
+
+// New returns a rows by cols grid with all cells of cost 1.
+func New(rows, cols int) *Grid {
+	g := &Grid{
+		Connectivity: Four,
+		rows:         rows,
+		cols:         cols,
+		costs:        make([]float64, rows*cols),
+		minCost:      1,
+	}
+	for i := range g.costs {
+		g.costs[i] = 1
+	}
+	return g
+}
+
+// Parse returns a grid drawn with strings, one per row: “*” is a wall,
+// “S” is the start, “F” is the finish, and anything else is an open cell
+// of cost 1. Rows shorter than the longest one are padded with walls.
+func Parse(lines []string) *Grid {
+	cols := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > cols {
+			cols = n
+		}
+	}
+
+	g := New(len(lines), cols)
+	for i, line := range lines {
+		runes := []rune(line)
+		for j := 0; j < cols; j++ {
+			switch {
+			case j >= len(runes) || runes[j] == '*':
+				g.SetWall(Point{i, j})
+			case runes[j] == 'S':
+				g.From = Point{i, j}
+			case runes[j] == 'F':
+				g.To = Point{i, j}
+			}
+		}
+	}
+	return g
+}
+
+// Rows returns the number of rows.
+func (g *Grid) Rows() int { return g.rows }
+
+// Cols returns the number of columns.
+func (g *Grid) Cols() int { return g.cols }
+
+// In tells whether p is inside the grid.
+func (g *Grid) In(p Point) bool {
+	return p.Row >= 0 && p.Col >= 0 && p.Row < g.rows && p.Col < g.cols
+}
+
+// Cost returns the terrain cost of p, +Inf for walls and cells outside the grid.
+func (g *Grid) Cost(p Point) float64 {
+	if !g.In(p) {
+		return math.Inf(1)
+	}
+	return g.costs[p.Row*g.cols+p.Col]
+}
+
+// SetCost sets the terrain cost of p. Costs must be positive;
+// math.Inf(1) makes p a wall.
+func (g *Grid) SetCost(p Point, cost float64) {
+	if !g.In(p) {
+		return
+	}
+	g.costs[p.Row*g.cols+p.Col] = cost
+	if cost < g.minCost {
+		g.minCost = cost
+	}
+}
+
+// SetWall makes p a wall.
+func (g *Grid) SetWall(p Point) { g.SetCost(p, math.Inf(1)) }
+
+// Uniform tells whether all cells that aren't walls cost 1.
+func (g *Grid) Uniform() bool {
+	for _, cost := range g.costs {
+		if cost != 1 && !math.IsInf(cost, 1) {
+			return false
+		}
+	}
+	return true
+}
+
+// Open tells whether p can be entered.
+func (g *Grid) Open(p Point) bool {
+	return g.In(p) && !math.IsInf(g.costs[p.Row*g.cols+p.Col], 1) && (g.CanEnter == nil || g.CanEnter(p))
+}
+
+// Passable is the same as Open for a row and a column.
+func (g *Grid) Passable(row, col int) bool { return g.Open(Point{row, col}) }
+
+// Start returns g.From.
+func (g *Grid) Start() Point { return g.From }
+
+// IsGoal tells whether p is g.To.
+func (g *Grid) IsGoal(p Point) bool { return p == g.To }
+
+// Goal returns g.To.
+func (g *Grid) Goal() Point { return g.To }
+
+// Heuristic estimates the path cost from p to g.To.
+func (g *Grid) Heuristic(p Point) float64 { return g.Distance(p, g.To) }
+
+// HeuristicFromStart estimates the path cost from g.From to p.
+func (g *Grid) HeuristicFromStart(p Point) float64 { return g.Distance(g.From, p) }
+
+// Distance estimates the path cost between any two cells.
+func (g *Grid) Distance(a, b Point) float64 {
+	metric := g.Metric
+	if metric == nil {
+		metric = heuristics.Manhattan
+		if g.Connectivity == Eight {
+			metric = heuristics.Octile
+		}
+	}
+	return metric(Location(a), Location(b)) * g.minCost
+}
+
+var (
+	straight = []Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+	diagonal = []Point{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}}
+)
+
+// Neighbors returns moves to the adjacent open cells.
+func (g *Grid) Neighbors(p Point) []astar.Edge[Point] {
+	edges := make([]astar.Edge[Point], 0, int(g.Connectivity))
+
+	for _, d := range straight {
+		if next := (Point{p.Row + d.Row, p.Col + d.Col}); g.Open(next) {
+			edges = append(edges, astar.Edge[Point]{To: next, Cost: g.Cost(next)})
+		}
+	}
+
+	if g.Connectivity != Eight {
+		return edges
+	}
+
+	for _, d := range diagonal {
+		next := Point{p.Row + d.Row, p.Col + d.Col}
+		if !g.Open(next) {
+			continue
+		}
+
+		walls := 0
+		if !g.Open(Point{p.Row + d.Row, p.Col}) {
+			walls++
+		}
+		if !g.Open(Point{p.Row, p.Col + d.Col}) {
+			walls++
+		}
+		if walls == 0 || walls == 1 && g.Corners >= OneCorner || g.Corners == AnyCorners {
+			edges = append(edges, astar.Edge[Point]{To: next, Cost: g.Cost(next) * math.Sqrt2})
+		}
+	}
+	return edges
+}
+
+// Predecessors returns moves into p from the adjacent open cells,
+// so that a Grid can be searched backwards.
+func (g *Grid) Predecessors(p Point) []astar.Edge[Point] {
+	if !g.Open(p) {
+		return nil
+	}
+
+	edges := []astar.Edge[Point]{}
+	for _, edge := range g.Neighbors(p) {
+		// Moves are symmetric except for the cost of entering the cell.
+		cost := g.Cost(p)
+		if edge.To.Row != p.Row && edge.To.Col != p.Col {
+			cost *= math.Sqrt2
+		}
+		edges = append(edges, astar.Edge[Point]{To: edge.To, Cost: cost})
+	}
+	return edges
+}
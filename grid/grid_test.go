@@ -0,0 +1,228 @@
+package grid_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pietv/astar"
+	. "github.com/pietv/astar/grid"
+	"github.com/pietv/astar/heuristics"
+	"github.com/pietv/astar/jps"
+)
+
+func random(rows, cols int, density float64, r *rand.Rand) *Grid {
+	g := New(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			switch {
+			case r.Float64() < density:
+				g.SetWall(Point{i, j})
+			case r.Intn(4) == 0:
+				g.SetCost(Point{i, j}, 1+float64(r.Intn(5)))
+			}
+		}
+	}
+	g.From = Point{r.Intn(rows), r.Intn(cols)}
+	g.To = Point{r.Intn(rows), r.Intn(cols)}
+	g.SetCost(g.From, 1)
+	g.SetCost(g.To, 1)
+	return g
+}
+
+func TestParse(t *testing.T) {
+	g := Parse([]string{
+		`S * `,
+		`  *`,
+		`    F`,
+	})
+
+	if g.Rows() != 3 || g.Cols() != 5 {
+		t.Fatalf("size: got %dx%d, expected 3x5", g.Rows(), g.Cols())
+	}
+	if g.From != (Point{0, 0}) || g.To != (Point{2, 4}) {
+		t.Errorf("got From %v, To %v", g.From, g.To)
+	}
+	for _, tt := range []struct {
+		p    Point
+		open bool
+	}{
+		{Point{0, 1}, true},
+		{Point{0, 2}, false},
+		{Point{1, 3}, false},
+		{Point{1, 4}, false},
+		{Point{2, 4}, true},
+		{Point{-1, 0}, false},
+		{Point{0, 5}, false},
+	} {
+		if g.Open(tt.p) != tt.open || g.Passable(tt.p.Row, tt.p.Col) != tt.open {
+			t.Errorf("%v: expected open %v", tt.p, tt.open)
+		}
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	a, b := Point{1, 2}, Point{4, -2}
+	for _, tt := range []struct {
+		name         string
+		connectivity Connectivity
+		metric       heuristics.Metric
+		expected     float64
+	}{
+		{"default, 4-connected", Four, nil, 7},
+		{"default, 8-connected", Eight, nil, 4 + 3*(math.Sqrt2-1)},
+		{"Euclidean", Eight, heuristics.Euclidean, 5},
+		{"Chebyshev", Eight, heuristics.Chebyshev, 4},
+	} {
+		g := New(1, 1)
+		g.Connectivity, g.Metric = tt.connectivity, tt.metric
+		if got := g.Distance(a, b); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: got %v, expected %v", tt.name, got, tt.expected)
+		}
+		if g.Distance(a, b) != g.Distance(b, a) {
+			t.Errorf("%s is not symmetric", tt.name)
+		}
+	}
+}
+
+func TestCorners(t *testing.T) {
+	// Diagonal moves out of the center cell.
+	g := Parse([]string{
+		` * `,
+		`*S `,
+		`   `,
+	})
+	g.Connectivity = Eight
+
+	for _, tt := range []struct {
+		corners  Corners
+		expected int
+	}{
+		{NoCorners, 2 + 1},
+		{OneCorner, 2 + 3},
+		{AnyCorners, 2 + 4},
+	} {
+		g.Corners = tt.corners
+		if got := len(g.Neighbors(g.From)); got != tt.expected {
+			t.Errorf("corners %d: got %d neighbors, expected %d", tt.corners, got, tt.expected)
+		}
+	}
+
+	// Squeezing between walls touching at corners.
+	g = Parse([]string{
+		`S*`,
+		`*F`,
+	})
+	g.Connectivity = Eight
+	for _, tt := range []struct {
+		corners Corners
+		found   bool
+	}{
+		{NoCorners, false},
+		{OneCorner, false},
+		{AnyCorners, true},
+	} {
+		g.Corners = tt.corners
+		_, err := astar.SearchResult[Point](context.Background(), g)
+		if (err == nil) != tt.found {
+			t.Errorf("corners %d: got %v", tt.corners, err)
+		}
+	}
+}
+
+func TestTerrain(t *testing.T) {
+	g := Parse([]string{
+		`S   F`,
+		`     `,
+	})
+	for j := 1; j < 4; j++ {
+		g.SetCost(Point{0, j}, 3)
+	}
+
+	res, err := astar.SearchResult[Point](context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Cost != 6 || len(res.Path) != 7 {
+		t.Errorf("got cost %v, path %v; expected a detour of cost 6", res.Cost, res.Path)
+	}
+
+	g.CanEnter = func(p Point) bool { return p.Row == 0 }
+	if res, err = astar.SearchResult[Point](context.Background(), g); err != nil || res.Cost != 10 {
+		t.Errorf("got %v, %v; expected the straight path of cost 10", res, err)
+	}
+}
+
+// Heuristics scaled by the cheapest terrain cost are consistent.
+func TestConsistency(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, c := range []Connectivity{Four, Eight} {
+		for _, metric := range []heuristics.Metric{nil, heuristics.Euclidean, heuristics.Chebyshev} {
+			g := random(12, 12, 0.2, r)
+			g.Connectivity = c
+			g.Metric = metric
+			g.SetCost(Point{0, 0}, 0.5)
+
+			violations, err := astar.Verify[Point](g, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(violations) > 0 {
+				t.Errorf("connectivity %d: %v", c, violations[0])
+			}
+		}
+	}
+}
+
+// Forward, backward and Jump Point searches agree on the shortest paths.
+func TestSearches(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		g := random(10+r.Intn(10), 10+r.Intn(10), 0.3, r)
+		g.Connectivity = []Connectivity{Four, Eight}[i%2]
+
+		res, err := astar.SearchResult[Point](context.Background(), g)
+		bidi, bidiErr := astar.SearchBidirectional[Point](context.Background(), g)
+		if (err == nil) != (bidiErr == nil) {
+			t.Fatalf("#%d: forward %v, bidirectional %v", i, err, bidiErr)
+		}
+		if err != nil {
+			continue
+		}
+		if math.Abs(res.Cost-bidi.Cost) > 1e-9 {
+			t.Errorf("#%d: forward cost %v, bidirectional %v", i, res.Cost, bidi.Cost)
+		}
+
+		// Jump Point Search only works on uniform-cost grids.
+		uniform := New(g.Rows(), g.Cols())
+		uniform.From, uniform.To, uniform.Connectivity = g.From, g.To, g.Connectivity
+		for i := 0; i < g.Rows(); i++ {
+			for j := 0; j < g.Cols(); j++ {
+				if !g.Open(Point{i, j}) {
+					uniform.SetWall(Point{i, j})
+				}
+			}
+		}
+		res, err = astar.SearchResult[Point](context.Background(), uniform)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jump, err := jps.Search(uniform, jps.Point{Row: g.From.Row, Col: g.From.Col},
+			jps.Point{Row: g.To.Row, Col: g.To.Col}, g.Connectivity == Eight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(res.Cost-jump.Cost) > 1e-9 {
+			t.Errorf("#%d: A* cost %v, Jump Point Search %v", i, res.Cost, jump.Cost)
+		}
+
+		if g.Uniform() {
+			continue
+		}
+		if _, err := jps.Search(g, jps.Point{Row: g.From.Row, Col: g.From.Col},
+			jps.Point{Row: g.To.Row, Col: g.To.Col}, g.Connectivity == Eight); err != jps.ErrNonUniform {
+			t.Errorf("#%d: Jump Point Search on terrain: got %v, want jps.ErrNonUniform", i, err)
+		}
+	}
+}
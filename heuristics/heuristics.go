@@ -0,0 +1,254 @@
+// Package heuristics provides common distance heuristics and ways
+// to combine them.
+//
+// A heuristic is a function estimating the path cost from a state to the goal.
+// Distance heuristics are built from a metric and the location of states:
+//
+//	h := heuristics.Distance(heuristics.Euclidean, location, goal)
+//
+// They can be combined, weighted and cached, and attached to an existing
+// problem in place of its own estimate:
+//
+//	h = heuristics.Memoize(heuristics.Max(h, p.Heuristic), 0)
+//	res, err := astar.SearchResult[S](ctx, heuristics.With(p, h))
+package heuristics
+
+import (
+	"container/list"
+	"math"
+	"sync"
+
+	"github.com/pietv/astar"
+)
+
+// Func estimates the path cost from a state to the goal.
+type Func[S any] func(S) float64
+
+// Point is a location on a plane, or a longitude (X) and a latitude (Y)
+// in degrees for Haversine.
+type Point struct {
+	X, Y float64
+}
+
+// Metric is the distance between two points.
+type Metric func(a, b Point) float64
+
+// Manhattan is the distance moving along the axes.
+func Manhattan(a, b Point) float64 {
+	return math.Abs(a.X-b.X) + math.Abs(a.Y-b.Y)
+}
+
+// Euclidean is the straight line distance.
+func Euclidean(a, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// Octile is the distance moving along the axes and diagonals,
+// with diagonal moves √2 times as long.
+func Octile(a, b Point) float64 {
+	dx, dy := math.Abs(a.X-b.X), math.Abs(a.Y-b.Y)
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// Chebyshev is the distance moving along the axes and diagonals,
+// with diagonal moves as long as the others.
+func Chebyshev(a, b Point) float64 {
+	return math.Max(math.Abs(a.X-b.X), math.Abs(a.Y-b.Y))
+}
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// Haversine is the great-circle distance in meters between two points
+// given as longitudes and latitudes in degrees.
+func Haversine(a, b Point) float64 {
+	rad := math.Pi / 180
+	dLat, dLon := (b.Y-a.Y)*rad, (b.X-a.X)*rad
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(a.Y*rad)*math.Cos(b.Y*rad)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Distance returns the metric distance from a state to goal,
+// with states located by at.
+func Distance[S any](metric Metric, at func(S) Point, goal S) Func[S] {
+	g := at(goal)
+	return func(s S) float64 { return metric(at(s), g) }
+}
+
+// Zero is the heuristic of Dijkstra's algorithm.
+func Zero[S any]() Func[S] {
+	return func(S) float64 { return 0 }
+}
+
+// Max returns the largest of the estimates. It is admissible and consistent
+// if all of them are.
+func Max[S any](hs ...Func[S]) Func[S] {
+	return func(s S) float64 {
+		best := 0.0
+		for _, h := range hs {
+			best = math.Max(best, h(s))
+		}
+		return best
+	}
+}
+
+// Weighted multiplies the estimate by w. Weights above 1 make the search
+// greedier: faster, but the path cost may be up to w times the optimal one.
+func Weighted[S any](w float64, h Func[S]) Func[S] {
+	return func(s S) float64 { return w * h(s) }
+}
+
+// Memoize caches the estimates of up to size most recently used states,
+// or of all states if size is 0. It is safe for concurrent use if h is.
+func Memoize[S comparable](h Func[S], size int) Func[S] {
+	type entry struct {
+		state    S
+		estimate float64
+	}
+	var (
+		mu    sync.Mutex
+		cache = map[S]*list.Element{}
+		lru   = list.New()
+	)
+	return func(s S) float64 {
+		mu.Lock()
+		if e, ok := cache[s]; ok {
+			lru.MoveToFront(e)
+			mu.Unlock()
+			return e.Value.(entry).estimate
+		}
+		mu.Unlock()
+
+		v := h(s)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := cache[s]; !ok {
+			cache[s] = lru.PushFront(entry{s, v})
+			if size > 0 && lru.Len() > size {
+				delete(cache, lru.Remove(lru.Back()).(entry).state)
+			}
+		}
+		return v
+	}
+}
+
+// With returns p with h as its heuristic. The result is also an astar.Keyer,
+// an astar.Reversible or has the Distance method of astar.Dynamic if p is
+// or has, with those methods of p. In particular, the reverse estimate
+// HeuristicFromStart and Distance are p's own rather than h:
+//
+//	r := heuristics.With[grid.Point](g, h).(astar.Reversible[grid.Point])
+//	res, err := astar.SearchBidirectional(ctx, r)
+func With[S comparable](p astar.Problem[S], h Func[S]) astar.Problem[S] {
+	base := &problem[S]{p, h}
+	k, key := p.(astar.Keyer[S])
+	r, reverse := p.(astar.Reversible[S])
+	d, distance := p.(distancer[S])
+
+	switch {
+	case key && reverse && distance:
+		return &struct {
+			*problem[S]
+			keyer[S]
+			reversible[S]
+			distancer[S]
+		}{base, keyer[S]{k}, reversible[S]{r}, d}
+	case key && reverse:
+		return &struct {
+			*problem[S]
+			keyer[S]
+			reversible[S]
+		}{base, keyer[S]{k}, reversible[S]{r}}
+	case key && distance:
+		return &struct {
+			*problem[S]
+			keyer[S]
+			distancer[S]
+		}{base, keyer[S]{k}, d}
+	case reverse && distance:
+		return &struct {
+			*problem[S]
+			reversible[S]
+			distancer[S]
+		}{base, reversible[S]{r}, d}
+	case key:
+		return &struct {
+			*problem[S]
+			keyer[S]
+		}{base, keyer[S]{k}}
+	case reverse:
+		return &struct {
+			*problem[S]
+			reversible[S]
+		}{base, reversible[S]{r}}
+	case distance:
+		return &struct {
+			*problem[S]
+			distancer[S]
+		}{base, d}
+	}
+	return base
+}
+
+type problem[S comparable] struct {
+	astar.Problem[S]
+	h Func[S]
+}
+
+func (p *problem[S]) Heuristic(s S) float64 { return p.h(s) }
+
+// keyer and reversible forward the methods of the wrapped problem
+// other than the ones of astar.Problem.
+type keyer[S comparable] struct{ k astar.Keyer[S] }
+
+func (k keyer[S]) Key(s S) interface{} { return k.k.Key(s) }
+
+type reversible[S comparable] struct{ r astar.Reversible[S] }
+
+func (r reversible[S]) Goal() S                          { return r.r.Goal() }
+func (r reversible[S]) Predecessors(s S) []astar.Edge[S] { return r.r.Predecessors(s) }
+func (r reversible[S]) HeuristicFromStart(s S) float64   { return r.r.HeuristicFromStart(s) }
+
+// distancer is the part of astar.Dynamic missing from astar.Problem.
+type distancer[S comparable] interface {
+	Distance(from, to S) float64
+}
+
+// WithInterface returns p with h as its Estimate. The result is also
+// an astar.Keyer[interface{}] if p is, with p's keys.
+func WithInterface(p astar.Interface, h Func[interface{}]) astar.Interface {
+	if k, ok := p.(astar.Keyer[interface{}]); ok {
+		return &struct {
+			*iface
+			keyer[interface{}]
+		}{&iface{p, h}, keyer[interface{}]{k}}
+	}
+	return &iface{p, h}
+}
+
+type iface struct {
+	astar.Interface
+	h Func[interface{}]
+}
+
+func (p *iface) Estimate(s interface{}) float64 { return p.h(s) }
+
+// WithG returns p with h as its Estimate. The result is also
+// an astar.Keyer[S] if p is, with p's keys.
+func WithG[S comparable](p astar.InterfaceG[S], h Func[S]) astar.InterfaceG[S] {
+	if k, ok := p.(astar.Keyer[S]); ok {
+		return &struct {
+			*ifaceG[S]
+			keyer[S]
+		}{&ifaceG[S]{p, h}, keyer[S]{k}}
+	}
+	return &ifaceG[S]{p, h}
+}
+
+type ifaceG[S comparable] struct {
+	astar.InterfaceG[S]
+	h Func[S]
+}
+
+func (p *ifaceG[S]) Estimate(s S) float64 { return p.h(s) }
@@ -0,0 +1,161 @@
+package heuristics_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/grid"
+	. "github.com/pietv/astar/heuristics"
+)
+
+func TestMetrics(t *testing.T) {
+	a, b := Point{X: 1, Y: 2}, Point{X: -3, Y: 5}
+	for _, tt := range []struct {
+		name     string
+		metric   Metric
+		expected float64
+	}{
+		{"Manhattan", Manhattan, 7},
+		{"Euclidean", Euclidean, 5},
+		{"Octile", Octile, 4 + 3*(math.Sqrt2-1)},
+		{"Chebyshev", Chebyshev, 4},
+	} {
+		if got := tt.metric(a, b); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: got %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+
+	// London to Paris is about 344 km.
+	london, paris := Point{X: -0.1278, Y: 51.5074}, Point{X: 2.3522, Y: 48.8566}
+	if got := Haversine(london, paris); math.Abs(got-343.5e3) > 1e3 {
+		t.Errorf("Haversine: got %v m", got)
+	}
+	if got := Haversine(Point{X: 0, Y: 0}, Point{X: 180, Y: 0}); math.Abs(got-math.Pi*EarthRadius) > 1e-6 {
+		t.Errorf("Haversine: got %v m for half the equator", got)
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	double := func(s int) float64 { return float64(2 * s) }
+	square := func(s int) float64 { return float64(s * s) }
+
+	if got := Max[int](double, square)(1); got != 2 {
+		t.Errorf("Max: got %v", got)
+	}
+	if got := Max[int](double, square, Zero[int]())(3); got != 9 {
+		t.Errorf("Max: got %v", got)
+	}
+	if got := Weighted[int](1.5, double)(2); got != 6 {
+		t.Errorf("Weighted: got %v", got)
+	}
+	if got := Distance(Manhattan, func(s int) Point { return Point{X: float64(s)} }, 10)(4); got != 6 {
+		t.Errorf("Distance: got %v", got)
+	}
+
+	calls := 0
+	h := Memoize(func(s int) float64 { calls++; return double(s) }, 2)
+	for _, s := range []int{1, 2, 2, 1, 3, 1, 2} {
+		if got := h(s); got != double(s) {
+			t.Errorf("Memoize: got %v for %v", got, s)
+		}
+	}
+	// 1 and 2 are cached, 3 evicts 2, the least recently used, and 2 evicts 3.
+	if calls != 4 {
+		t.Errorf("Memoize: got %d calls, expected 4", calls)
+	}
+}
+
+// number counts to 50 with moves of 1 and 7 without a useful estimate.
+type number struct{ curr int }
+
+func (n number) Start() interface{}             { return 0 }
+func (n number) Finish() bool                   { return n.curr == 50 }
+func (n *number) Move(x interface{})            { n.curr = x.(int) }
+func (n number) Successors() []interface{}      { return []interface{}{n.curr + 1, n.curr + 7} }
+func (n number) Cost(x interface{}) float64     { return 1 }
+func (n number) Estimate(x interface{}) float64 { return 0 }
+
+func TestWith(t *testing.T) {
+	// At most 7 per move.
+	remaining := func(s int) float64 { return math.Max(0, math.Ceil(float64(50-s)/7)) }
+
+	path, steps, err := astar.Search(&number{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hpath, hsteps, err := astar.Search(WithInterface(&number{}, func(s interface{}) float64 { return remaining(s.(int)) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hpath) != len(path) || len(hsteps) >= len(steps) {
+		t.Errorf("got a path of %d states in %d steps, expected %d states in fewer than %d steps",
+			len(hpath), len(hsteps), len(path), len(steps))
+	}
+
+	_, gsteps, err := astar.SearchG[int](WithG[int](&typed{}, remaining))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gsteps) != len(hsteps) {
+		t.Errorf("WithG: got %d steps, expected %d", len(gsteps), len(hsteps))
+	}
+
+	g := grid.New(20, 20)
+	g.To = grid.Point{Row: 19, Col: 19}
+	at := func(p grid.Point) Point { return Point{X: float64(p.Col), Y: float64(p.Row)} }
+	dijkstra, err := astar.SearchResult[grid.Point](context.Background(), With[grid.Point](g, Zero[grid.Point]()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manhattan, err := astar.SearchResult[grid.Point](context.Background(), With(g, Memoize(Distance(Manhattan, at, g.To), 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dijkstra.Cost != manhattan.Cost || manhattan.Expanded >= dijkstra.Expanded {
+		t.Errorf("got cost %v in %d expansions, expected %v in fewer than %d",
+			manhattan.Cost, manhattan.Expanded, dijkstra.Cost, dijkstra.Expanded)
+	}
+
+	// The wrapped grid is still Reversible and Dynamic.
+	r, ok := With[grid.Point](g, Zero[grid.Point]()).(astar.Reversible[grid.Point])
+	if !ok {
+		t.Fatal("With: the grid is no longer Reversible")
+	}
+	if res, err := astar.SearchBidirectional(context.Background(), r); err != nil || res.Cost != dijkstra.Cost {
+		t.Errorf("SearchBidirectional: got %v, %v", res, err)
+	}
+	if _, ok := r.(astar.Dynamic[grid.Point]); !ok {
+		t.Error("With: the grid is no longer Dynamic")
+	}
+	if _, ok := With[int](astar.AdaptG[int](&typed{}), remaining).(astar.Reversible[int]); ok {
+		t.Error("With: an adapted Interface has become Reversible")
+	}
+
+	// So is a Keyer, which needs its keys for slices.
+	if path, _, err := astar.SearchContext(context.Background(), WithInterface(&slices{}, func(interface{}) float64 { return 0 })); err != nil || len(path) != 4 {
+		t.Errorf("WithInterface with a Keyer: got %v, %v", path, err)
+	}
+}
+
+// slices counts to 3 in a slice, which needs a key.
+type slices struct{ curr []int }
+
+func (n slices) Start() interface{}             { return []int{0} }
+func (n slices) Finish() bool                   { return n.curr[0] == 3 }
+func (n *slices) Move(x interface{})            { n.curr = x.([]int) }
+func (n slices) Successors() []interface{}      { return []interface{}{[]int{n.curr[0] + 1}} }
+func (n slices) Cost(x interface{}) float64     { return 1 }
+func (n slices) Estimate(x interface{}) float64 { return 0 }
+func (n slices) Key(x interface{}) interface{}  { return x.([]int)[0] }
+
+// typed is number for SearchG.
+type typed struct{ curr int }
+
+func (n typed) Start() int             { return 0 }
+func (n typed) Finish() bool           { return n.curr == 50 }
+func (n *typed) Move(x int)            { n.curr = x }
+func (n typed) Successors() []int      { return []int{n.curr + 1, n.curr + 7} }
+func (n typed) Cost(x int) float64     { return 1 }
+func (n typed) Estimate(x int) float64 { return 0 }
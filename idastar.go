@@ -0,0 +1,174 @@
+package astar
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// IDAStar finds the shortest path the same way Search does, but with
+// the iterative deepening A* algorithm (IDA*): a series of depth-first
+// searches, each one bounded by the smallest Cost() + Estimate() value
+// that exceeded the bound of the previous one.
+//
+// IDAStar keeps only the current path in memory, so it can solve problems
+// where Search runs out of memory on its priority queue and explored set.
+// In exchange, it re-explores states: once per iteration, and once per path
+// that leads to them. Recording the explored states would take memory
+// growing with the number of those re-explorations, so IDAStar returns
+// an empty slice of them. Use IDAStarContext() to have them recorded.
+func IDAStar(p Interface) ([]interface{}, []interface{}, error) {
+	res, err := idaStar(context.Background(), Adapt(p), newConfig([]Option{LastSteps(0)}))
+	return res.Path, res.Steps, err
+}
+
+// IDAStarContext is the same as IDAStar, but stops when ctx is done or
+// when the MaxExpanded() limit is hit. Of the other options, it respects
+// LastSteps(), Key(), RecoverPanics() and Observe() Expand and Generate
+// callbacks.
+func IDAStarContext(ctx context.Context, p Interface, opts ...Option) ([]interface{}, []interface{}, error) {
+	res, err := idaStar(ctx, Adapt(p), newContextConfig(opts))
+	return res.Path, res.Steps, err
+}
+
+// IDAStarResult is the same as IDAStarContext for a Problem, returning a Result.
+// The Result's Expanded and Generated count states on every iteration,
+// and its MaxFrontier is the maximum path length.
+func IDAStarResult[S comparable](ctx context.Context, p Problem[S], opts ...Option) (*Result[S], error) {
+	return idaStar(ctx, p, newContextConfig(opts))
+}
+
+func idaStar[S comparable](ctx context.Context, p Problem[S], c *config) (res *Result[S], err error) {
+	res = &Result[S]{}
+	begin := time.Now()
+	defer func() { res.Duration = time.Since(begin) }()
+
+	observer, err := observerFor[S](c)
+	if err != nil {
+		return res, err
+	}
+
+	done := ctx.Done()
+
+	steps := newTrail[S](c.lastSteps)
+	defer func() { res.Steps = steps.slice() }()
+
+	key, err := keyFor[S](c, p)
+	if err != nil {
+		return res, err
+	}
+	useKey(p, key)
+
+	// Return panics in p's methods as errors.
+	if c.recoverPanics {
+		g := newGuard(p)
+		if key != nil {
+			key = g.keys(key)
+		}
+		p = g
+		defer g.catch(&err)
+	}
+
+	// The current path with path costs and estimates of its states,
+	// and the keys of the states on it.
+	start := p.Start()
+	path := []Node[S]{{State: start, H: p.Heuristic(start)}}
+	onPath := newStateMap[S, bool](key, 0)
+	onPath.set(start, true)
+	res.Generated, res.MaxFrontier = 1, 1
+
+	var (
+		found   bool
+		failure error
+	)
+
+	// dfs explores the last path state and its successors within the bound.
+	// It returns the smallest Cost() + Estimate() value exceeding the bound.
+	var dfs func(bound float64) float64
+	dfs = func(bound float64) float64 {
+		curr := path[len(path)-1]
+		if f := curr.G + curr.H; f > bound {
+			return f
+		}
+
+		select {
+		case <-done:
+			failure = ctx.Err()
+			return math.Inf(1)
+		default:
+		}
+		if c.maxExpanded > 0 && res.Expanded >= c.maxExpanded {
+			failure = &LimitError{"expanded", c.maxExpanded}
+			return math.Inf(1)
+		}
+
+		res.Expanded++
+		steps.add(curr.State)
+		if observer.Expand != nil {
+			e := Event[S]{State: curr.State, G: curr.G, H: curr.H}
+			if len(path) > 1 {
+				e.Parent = path[len(path)-2].State
+			}
+			observer.Expand(e)
+		}
+
+		if p.IsGoal(curr.State) {
+			found = true
+			return curr.G + curr.H
+		}
+
+		min := math.Inf(1)
+		for _, edge := range p.Neighbors(curr.State) {
+			// Don't go in circles.
+			if _, ok := onPath.get(edge.To); ok {
+				continue
+			}
+
+			succ := Node[S]{State: edge.To, G: curr.G + edge.Cost, H: p.Heuristic(edge.To)}
+			succ.F = succ.G + succ.H
+			res.Generated++
+			if observer.Generate != nil {
+				observer.Generate(Event[S]{succ.State, curr.State, succ.G, succ.H})
+			}
+
+			path = append(path, succ)
+			onPath.set(succ.State, true)
+			if len(path) > res.MaxFrontier {
+				res.MaxFrontier = len(path)
+			}
+
+			t := dfs(bound)
+			if found || failure != nil {
+				return t
+			}
+
+			onPath.delete(succ.State)
+			path = path[:len(path)-1]
+
+			if t < min {
+				min = t
+			}
+		}
+		return min
+	}
+
+	path[0].F = path[0].H
+	for bound := path[0].F; ; {
+		t := dfs(bound)
+		switch {
+		case failure != nil:
+			return res, failure
+		case found:
+			res.Nodes = path
+			res.Path = make([]S, len(path))
+			for i, node := range path {
+				res.Path[i] = node.State
+			}
+			res.Cost = path[len(path)-1].G
+			return res, nil
+		case math.IsInf(t, 1):
+			return res, ErrNotFound
+		}
+		bound = t
+	}
+}
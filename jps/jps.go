@@ -0,0 +1,349 @@
+// Package jps implements Jump Point Search by Daniel Harabor and Alban Grastien,
+// “Online Graph Pruning for Pathfinding on Grid Maps”, AAAI 2011.
+//
+// Jump Point Search is A* for uniform-cost grids which skips over the cells
+// where any shortest path could be replaced with another one of the same
+// length. Instead of putting every neighbor cell on the priority queue,
+// it “jumps” in a straight line until it hits a wall or a cell where
+// the path may have to turn (a jump point). It finds paths as short as A*
+// does while exploring far fewer cells in open areas.
+//
+// Both 4-connected and 8-connected grids are supported. On 8-connected grids
+// diagonal moves cost √2 and are only allowed if both cells next to them
+// are passable, that is the path never cuts wall corners.
+package jps
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/heuristics"
+)
+
+// Grid tells which cells can be walked through.
+type Grid interface {
+	// Is the cell at the given row and column passable? Must be false
+	// for cells outside the grid.
+	Passable(row, col int) bool
+}
+
+// Uniform is implemented by grids which may have cells of different costs.
+// Jump Point Search assumes that moving into any passable cell costs 1,
+// so Search returns ErrNonUniform if Uniform() returns false.
+type Uniform interface {
+	Uniform() bool
+}
+
+// ErrNonUniform means that the grid has passable cells costing other than 1.
+var ErrNonUniform = errors.New("jps: grid costs are not uniform")
+
+// Point is a grid cell location.
+type Point struct {
+	Row, Col int
+}
+
+// Search finds the shortest path from start to finish on an 8-connected
+// grid if diagonal is true, or on a 4-connected grid otherwise.
+// The Result's Path lists every cell along the way, while Steps
+// are the jump points explored. If finish is not reachable,
+// astar.ErrNotFound is returned.
+func Search(g Grid, start, finish Point, diagonal bool) (*astar.Result[Point], error) {
+	if u, ok := g.(Uniform); ok && !u.Uniform() {
+		return &astar.Result[Point]{Steps: []Point{}}, ErrNonUniform
+	}
+
+	s := &search{
+		g:        g,
+		finish:   finish,
+		diagonal: diagonal,
+		metric:   heuristics.Manhattan,
+		nodes:    map[Point]*node{},
+	}
+	if diagonal {
+		s.metric = heuristics.Octile
+	}
+	return s.run(start)
+}
+
+// node is a jump point with its path cost (g) and estimate (h).
+type node struct {
+	p      Point
+	g, h   float64
+	parent *node
+	index  int
+	closed bool
+}
+
+type nodes []*node
+
+func (pq nodes) Len() int           { return len(pq) }
+func (pq nodes) Less(i, j int) bool { return pq[i].g+pq[i].h < pq[j].g+pq[j].h }
+func (pq nodes) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+func (pq *nodes) Push(x interface{}) {
+	n := x.(*node)
+	n.index = len(*pq)
+	*pq = append(*pq, n)
+}
+
+func (pq *nodes) Pop() interface{} {
+	old := *pq
+	n := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return n
+}
+
+type search struct {
+	g        Grid
+	finish   Point
+	diagonal bool
+	metric   heuristics.Metric
+	nodes    map[Point]*node
+}
+
+func (s *search) run(start Point) (*astar.Result[Point], error) {
+	res := &astar.Result[Point]{Steps: []Point{}}
+	begin := time.Now()
+	defer func() { res.Duration = time.Since(begin) }()
+
+	if !s.g.Passable(start.Row, start.Col) {
+		return res, astar.ErrNotFound
+	}
+
+	first := &node{p: start, h: s.distance(start, s.finish)}
+	s.nodes[start] = first
+	pq := nodes{first}
+	res.Generated, res.MaxFrontier = 1, 1
+
+	for len(pq) > 0 {
+		current := heap.Pop(&pq).(*node)
+		current.closed = true
+		res.Expanded++
+		res.Steps = append(res.Steps, current.p)
+
+		if current.p == s.finish {
+			s.path(res, current)
+			return res, nil
+		}
+
+		for _, dir := range s.directions(current) {
+			jump, ok := s.jump(current.p, dir)
+			if !ok {
+				continue
+			}
+
+			g := current.g + s.distance(current.p, jump)
+			n, seen := s.nodes[jump]
+			switch {
+			case !seen:
+				n = &node{p: jump, g: g, h: s.distance(jump, s.finish), parent: current}
+				s.nodes[jump] = n
+				heap.Push(&pq, n)
+				res.Generated++
+				if len(pq) > res.MaxFrontier {
+					res.MaxFrontier = len(pq)
+				}
+			case !n.closed && g < n.g:
+				n.g, n.parent = g, current
+				heap.Fix(&pq, n.index)
+				res.Updated++
+			}
+		}
+	}
+
+	return res, astar.ErrNotFound
+}
+
+// path fills in the path from the start to the given jump point,
+// with the cells in between the jump points.
+func (s *search) path(res *astar.Result[Point], last *node) {
+	jumps := []*node{}
+	for n := last; n != nil; n = n.parent {
+		jumps = append(jumps, n)
+	}
+
+	for i := len(jumps) - 1; i >= 0; i-- {
+		from := jumps[i]
+		res.Path = append(res.Path, from.p)
+		res.Nodes = append(res.Nodes, astar.Node[Point]{State: from.p, G: from.g, H: from.h, F: from.g + from.h})
+		if i == 0 {
+			break
+		}
+
+		// Cells between the jump points lie on a straight or a diagonal line.
+		to := jumps[i-1].p
+		dir := Point{sign(to.Row - from.p.Row), sign(to.Col - from.p.Col)}
+		step := s.distance(Point{}, dir)
+		g := from.g
+		for p := (Point{from.p.Row + dir.Row, from.p.Col + dir.Col}); p != to; p = (Point{p.Row + dir.Row, p.Col + dir.Col}) {
+			g += step
+			h := s.distance(p, s.finish)
+			res.Path = append(res.Path, p)
+			res.Nodes = append(res.Nodes, astar.Node[Point]{State: p, G: g, H: h, F: g + h})
+		}
+	}
+	res.Cost = last.g
+}
+
+// directions returns the directions worth jumping to from n: all of them
+// from the start, and only the ones with no equally short alternative
+// paths avoiding n otherwise.
+func (s *search) directions(n *node) []Point {
+	r, c := n.p.Row, n.p.Col
+
+	if n.parent == nil {
+		dirs := []Point{}
+		for _, d := range []Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			if s.g.Passable(r+d.Row, c+d.Col) {
+				dirs = append(dirs, d)
+			}
+		}
+		if s.diagonal {
+			for _, d := range []Point{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+				if s.g.Passable(r+d.Row, c) && s.g.Passable(r, c+d.Col) && s.g.Passable(r+d.Row, c+d.Col) {
+					dirs = append(dirs, d)
+				}
+			}
+		}
+		return dirs
+	}
+
+	dr, dc := sign(r-n.parent.p.Row), sign(c-n.parent.p.Col)
+	dirs := []Point{}
+	add := func(dr, dc int) {
+		if s.g.Passable(r+dr, c+dc) {
+			dirs = append(dirs, Point{dr, dc})
+		}
+	}
+
+	switch {
+	case dr != 0 && dc != 0:
+		// Diagonal: keep going, or turn to either side of the diagonal.
+		add(dr, 0)
+		add(0, dc)
+		if s.g.Passable(r+dr, c) && s.g.Passable(r, c+dc) {
+			add(dr, dc)
+		}
+	case !s.diagonal:
+		// Straight on a 4-connected grid: keep going or turn.
+		add(dr, dc)
+		add(dc, dr)
+		add(-dc, -dr)
+	case dc != 0:
+		// Horizontal on an 8-connected grid: keep going, turn,
+		// or go diagonally on the way to the turns.
+		next, up, down := s.g.Passable(r, c+dc), s.g.Passable(r-1, c), s.g.Passable(r+1, c)
+		if next {
+			add(0, dc)
+			if up {
+				add(-1, dc)
+			}
+			if down {
+				add(1, dc)
+			}
+		}
+		if up {
+			add(-1, 0)
+		}
+		if down {
+			add(1, 0)
+		}
+	default:
+		// Vertical on an 8-connected grid.
+		next, left, right := s.g.Passable(r+dr, c), s.g.Passable(r, c-1), s.g.Passable(r, c+1)
+		if next {
+			add(dr, 0)
+			if left {
+				add(dr, -1)
+			}
+			if right {
+				add(dr, 1)
+			}
+		}
+		if left {
+			add(0, -1)
+		}
+		if right {
+			add(0, 1)
+		}
+	}
+	return dirs
+}
+
+// jump moves from p in the given direction until it finds a jump point:
+// the finish, or a cell where a shortest path may have to turn.
+func (s *search) jump(p, dir Point) (Point, bool) {
+	dr, dc := dir.Row, dir.Col
+	for {
+		r, c := p.Row+dr, p.Col+dc
+		if !s.g.Passable(r, c) {
+			return Point{}, false
+		}
+		p = Point{r, c}
+		if p == s.finish {
+			return p, true
+		}
+
+		switch {
+		case dr != 0 && dc != 0:
+			// A jump point if there is one straight along either side.
+			if _, ok := s.jump(p, Point{dr, 0}); ok {
+				return p, true
+			}
+			if _, ok := s.jump(p, Point{0, dc}); ok {
+				return p, true
+			}
+			if !s.g.Passable(r+dr, c) || !s.g.Passable(r, c+dc) {
+				return Point{}, false
+			}
+		case dc != 0:
+			// A jump point if a wall behind to the side ends here.
+			if s.g.Passable(r-1, c) && !s.g.Passable(r-1, c-dc) ||
+				s.g.Passable(r+1, c) && !s.g.Passable(r+1, c-dc) {
+				return p, true
+			}
+		default:
+			if s.g.Passable(r, c-1) && !s.g.Passable(r-dr, c-1) ||
+				s.g.Passable(r, c+1) && !s.g.Passable(r-dr, c+1) {
+				return p, true
+			}
+			if !s.diagonal {
+				// On a 4-connected grid, a vertical line is a jump point
+				// if there is one straight to either side.
+				if _, ok := s.jump(p, Point{0, -1}); ok {
+					return p, true
+				}
+				if _, ok := s.jump(p, Point{0, 1}); ok {
+					return p, true
+				}
+			}
+		}
+	}
+}
+
+// distance is octile distance on 8-connected grids and Manhattan distance
+// on 4-connected ones.
+func (s *search) distance(a, b Point) float64 {
+	return s.metric(location(a), location(b))
+}
+
+// location returns the location of p for heuristics metrics.
+func location(p Point) heuristics.Point {
+	return heuristics.Point{X: float64(p.Col), Y: float64(p.Row)}
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package jps_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/pietv/astar"
+	. "github.com/pietv/astar/jps"
+)
+
+// grid is a rectangular grid with walls, searchable by plain A*
+// for comparison.
+type grid struct {
+	walls         [][]bool
+	start, finish Point
+	diagonal      bool
+}
+
+func newGrid(rows, cols int, density float64, diagonal bool, r *rand.Rand) *grid {
+	g := &grid{walls: make([][]bool, rows), diagonal: diagonal}
+	for i := range g.walls {
+		g.walls[i] = make([]bool, cols)
+		for j := range g.walls[i] {
+			g.walls[i][j] = r.Float64() < density
+		}
+	}
+	g.start = Point{r.Intn(rows), r.Intn(cols)}
+	g.finish = Point{r.Intn(rows), r.Intn(cols)}
+	g.walls[g.start.Row][g.start.Col] = false
+	g.walls[g.finish.Row][g.finish.Col] = false
+	return g
+}
+
+func (g *grid) Passable(row, col int) bool {
+	return row >= 0 && col >= 0 && row < len(g.walls) && col < len(g.walls[row]) && !g.walls[row][col]
+}
+
+func (g *grid) Start() Point        { return g.start }
+func (g *grid) IsGoal(p Point) bool { return p == g.finish }
+func (g *grid) Heuristic(p Point) float64 {
+	dr, dc := math.Abs(float64(p.Row-g.finish.Row)), math.Abs(float64(p.Col-g.finish.Col))
+	if !g.diagonal {
+		return dr + dc
+	}
+	return math.Max(dr, dc) + (math.Sqrt2-1)*math.Min(dr, dc)
+}
+func (g *grid) Neighbors(p Point) []astar.Edge[Point] {
+	edges := []astar.Edge[Point]{}
+	for _, d := range []Point{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		if g.Passable(p.Row+d.Row, p.Col+d.Col) {
+			edges = append(edges, astar.Edge[Point]{To: Point{p.Row + d.Row, p.Col + d.Col}, Cost: 1})
+		}
+	}
+	if g.diagonal {
+		for _, d := range []Point{{-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+			if g.Passable(p.Row+d.Row, p.Col) && g.Passable(p.Row, p.Col+d.Col) && g.Passable(p.Row+d.Row, p.Col+d.Col) {
+				edges = append(edges, astar.Edge[Point]{To: Point{p.Row + d.Row, p.Col + d.Col}, Cost: math.Sqrt2})
+			}
+		}
+	}
+	return edges
+}
+
+func TestSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, diagonal := range []bool{false, true} {
+		for i := 0; i < 300; i++ {
+			g := newGrid(5+r.Intn(30), 5+r.Intn(30), r.Float64()*0.4, diagonal, r)
+
+			want, wantErr := astar.SearchResult[Point](context.Background(), g)
+			res, err := Search(g, g.start, g.finish, diagonal)
+			if err != wantErr {
+				t.Fatalf("diagonal %v, grid #%d: got error %v, want %v", diagonal, i, err, wantErr)
+			}
+			if err != nil {
+				continue
+			}
+			if math.Abs(res.Cost-want.Cost) > 1e-9 {
+				t.Fatalf("diagonal %v, grid #%d: got cost %v, want %v", diagonal, i, res.Cost, want.Cost)
+			}
+
+			// The path goes through adjacent cells.
+			if res.Path[0] != g.start || res.Path[len(res.Path)-1] != g.finish {
+				t.Fatalf("diagonal %v, grid #%d: got path %v", diagonal, i, res.Path)
+			}
+			for j := 1; j < len(res.Path); j++ {
+				a, b := res.Path[j-1], res.Path[j]
+				if dr, dc := b.Row-a.Row, b.Col-a.Col; dr*dr+dc*dc > 2 || !diagonal && dr*dr+dc*dc > 1 || !g.Passable(b.Row, b.Col) {
+					t.Fatalf("diagonal %v, grid #%d: got path %v", diagonal, i, res.Path)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenGrid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, diagonal := range []bool{false, true} {
+		g := newGrid(50, 50, 0, diagonal, r)
+		g.start, g.finish = Point{0, 0}, Point{49, 30}
+
+		want, _ := astar.SearchResult[Point](context.Background(), g)
+		res, _ := Search(g, g.start, g.finish, diagonal)
+		if res.Expanded*10 > want.Expanded {
+			t.Errorf("diagonal %v: got %d expanded, want far fewer than A* %d", diagonal, res.Expanded, want.Expanded)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package astar
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Keyer is implemented by problems whose states can't be told apart
+// with ==, such as slices, maps, or structs containing them, which would
+// make the search panic. Key returns a comparable value identifying
+// the given state, such as a string or an array: states with equal keys
+// are the same state.
+//
+// An Interface implements Keyer[interface{}] with the method
+//
+//	Key(state interface{}) interface{}
+//
+// and an InterfaceG[S] or a Problem[S] implements Keyer[S].
+type Keyer[S comparable] interface {
+	Key(S) interface{}
+}
+
+// Key makes the search tell states apart by key(state) rather than by
+// the states themselves, the same as a Keyer does. Paths and explored
+// states are still made of the original states. Its state type must be
+// the same as the one of the problem being solved. For Search(),
+// SearchContext() and Adapt() that is interface{}:
+//
+//	astar.SearchContext(ctx, p, astar.Key(func(state interface{}) interface{} {
+//		return string(state.([]byte))
+//	}))
+//
+// Keys are used by the Search, SearchProblem, SearchResult, IDAStar and
+// KShortest functions and by Verify. SearchBidirectional, SearchAnytime
+// and Planner don't support them and return an error for a Key or
+// a Keyer.
+func Key[S comparable](key func(state S) interface{}) Option {
+	return func(c *config) { c.key = key }
+}
+
+// ErrKeysUnsupported is returned by the algorithms that can't tell states
+// apart by key for a problem with a Key() option or a Keyer.
+var ErrKeysUnsupported = errors.New("astar: keys are not supported by this algorithm")
+
+// keyFor returns the key function set in c for states of type S,
+// or the Key method of p, or nil if there is none.
+func keyFor[S comparable](c *config, p Problem[S]) (func(S) interface{}, error) {
+	if c.key != nil {
+		key, ok := c.key.(func(S) interface{})
+		if !ok {
+			return nil, fmt.Errorf("astar: key function %T does not match %T", c.key, key)
+		}
+		return key, nil
+	}
+
+	if a, ok := p.(*adapter[S]); ok {
+		if k, ok := a.p.(Keyer[S]); ok {
+			return k.Key, nil
+		}
+	}
+	if k, ok := p.(Keyer[S]); ok {
+		return k.Key, nil
+	}
+	return nil, nil
+}
+
+// noKeys returns ErrKeysUnsupported if there is a key function for p.
+func noKeys[S comparable](c *config, p Problem[S]) error {
+	key, err := keyFor(c, p)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		return ErrKeysUnsupported
+	}
+	return nil
+}
+
+// useKey makes an adapter compare the states it is moved to by key.
+func useKey[S comparable](p Problem[S], key func(S) interface{}) {
+	if a, ok := p.(*adapter[S]); ok {
+		a.key = key
+	}
+}
+
+// stateMap maps states to values by their keys, or by the states
+// themselves if key is nil, so that those don't pay for the keys.
+type stateMap[S comparable, V any] struct {
+	key    func(S) interface{}
+	states map[S]V
+	keys   map[interface{}]V
+}
+
+func newStateMap[S comparable, V any](key func(S) interface{}, size int) *stateMap[S, V] {
+	if key == nil {
+		return &stateMap[S, V]{states: make(map[S]V, size)}
+	}
+	return &stateMap[S, V]{key: key, keys: make(map[interface{}]V, size)}
+}
+
+func (m *stateMap[S, V]) get(s S) (V, bool) {
+	if m.key == nil {
+		v, ok := m.states[s]
+		return v, ok
+	}
+	v, ok := m.keys[m.key(s)]
+	return v, ok
+}
+
+func (m *stateMap[S, V]) set(s S, v V) {
+	if m.key == nil {
+		m.states[s] = v
+	} else {
+		m.keys[m.key(s)] = v
+	}
+}
+
+func (m *stateMap[S, V]) delete(s S) {
+	if m.key == nil {
+		delete(m.states, s)
+	} else {
+		delete(m.keys, m.key(s))
+	}
+}
+
+func (m *stateMap[S, V]) len() int {
+	if m.key == nil {
+		return len(m.states)
+	}
+	return len(m.keys)
+}
+
+// same tells whether a and b are the same state by key.
+func same[S comparable](key func(S) interface{}, a, b S) bool {
+	if key == nil {
+		return a == b
+	}
+	return key(a) == key(b)
+}
@@ -0,0 +1,189 @@
+package astar
+
+import (
+	"context"
+	"errors"
+)
+
+// KShortest finds up to k shortest loopless paths from p.Start() to
+// the p.Finish() state, in the order of increasing path costs.
+// p is moved to the states along the found paths over and over again,
+// so it must tell correct successors for any state it is moved to.
+// ErrNotFound is returned if there is no path at all.
+func KShortest(p Interface, k int) ([][]interface{}, error) {
+	results, err := KShortestPaths(context.Background(), Adapt(p), k, RecoverPanics(false))
+	paths := make([][]interface{}, len(results))
+	for i, res := range results {
+		paths[i] = res.Path
+	}
+	return paths, err
+}
+
+// KShortestPaths is the same as KShortest for a Problem. It uses Yen's
+// algorithm: every next path deviates from one of the paths found before
+// at some spur state, and the rest of it is found with an A* search from
+// the spur state which excludes the moves taken by the found paths there
+// and the states before the spur state. The options are passed to those
+// searches.
+//
+// The Results have Path, Nodes and Cost set for whole paths, while
+// the statistics are those of the search that found the rest of the path.
+// If a search fails with an error other than ErrNotFound, the paths found
+// so far are returned along with it. So are panics in p's methods,
+// including the ones called to compare the paths, unless told otherwise
+// with RecoverPanics(false).
+func KShortestPaths[S comparable](ctx context.Context, p Problem[S], k int, opts ...Option) (found []*Result[S], err error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	c := newContextConfig(opts)
+	key, err := keyFor(c, p)
+	if err != nil {
+		return nil, err
+	}
+	useKey(p, key)
+
+	// Return panics in p's methods as errors.
+	if c.recoverPanics {
+		g := newGuard(p)
+		if key != nil {
+			key = g.keys(key)
+		}
+		p = g
+		defer g.catch(&err)
+	}
+
+	// The searches of the restricted problems need the key of p.
+	if key != nil {
+		opts = append(opts[:len(opts):len(opts)], Key(key))
+	}
+
+	first, err := SearchResult(ctx, p, opts...)
+	if err != nil {
+		return nil, err
+	}
+	found = []*Result[S]{first}
+
+	// Candidates for the next path.
+	candidates := []*Result[S]{}
+
+	for len(found) < k {
+		prev := found[len(found)-1]
+
+		for i := 0; i < len(prev.Path)-1; i++ {
+			spur, root := prev.Nodes[i], prev.Nodes[:i+1]
+
+			r := &restricted[S]{
+				Problem: p,
+				key:     key,
+				start:   spur.State,
+				states:  newStateMap[S, bool](key, i),
+				next:    newStateMap[S, bool](key, 0),
+			}
+
+			// Don't take the same moves from the spur state as
+			// the found paths with the same root.
+			for _, path := range found {
+				if len(path.Path) > i+1 && samePath(key, path.Path[:i+1], prev.Path[:i+1]) {
+					r.next.set(path.Path[i+1], true)
+				}
+			}
+
+			// Don't go back to the root states.
+			for _, node := range root[:i] {
+				r.states.set(node.State, true)
+			}
+
+			res, err := SearchResult[S](ctx, r, opts...)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return found, err
+			}
+
+			// Join the root and the rest of the path.
+			path := append([]Node[S]{}, root[:i]...)
+			for _, node := range res.Nodes {
+				node.G += spur.G
+				node.F += spur.G
+				path = append(path, node)
+			}
+			res.Nodes, res.Path = path, make([]S, len(path))
+			for j, node := range path {
+				res.Path[j] = node.State
+			}
+			res.Cost = path[len(path)-1].G
+
+			if !containsPath(key, candidates, res.Path) && !containsPath(key, found, res.Path) {
+				candidates = append(candidates, res)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		// Take the cheapest candidate.
+		min := 0
+		for i, res := range candidates {
+			if res.Cost < candidates[min].Cost {
+				min = i
+			}
+		}
+		found = append(found, candidates[min])
+		candidates = append(candidates[:min], candidates[min+1:]...)
+	}
+
+	return found, nil
+}
+
+// restricted is a Problem starting at a given state, without some
+// of the states of the original one and some of the moves from the start.
+// States are told apart by key, if there is one.
+type restricted[S comparable] struct {
+	Problem[S]
+	key    func(S) interface{}
+	start  S
+	states *stateMap[S, bool]
+	next   *stateMap[S, bool]
+}
+
+func (r *restricted[S]) Start() S { return r.start }
+
+func (r *restricted[S]) Neighbors(s S) []Edge[S] {
+	edges := []Edge[S]{}
+	fromStart := same(r.key, s, r.start)
+	for _, edge := range r.Problem.Neighbors(s) {
+		if _, ok := r.states.get(edge.To); ok {
+			continue
+		}
+		if _, ok := r.next.get(edge.To); ok && fromStart {
+			continue
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+func samePath[S comparable](key func(S) interface{}, a, b []S) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !same(key, a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPath[S comparable](key func(S) interface{}, results []*Result[S], path []S) bool {
+	for _, res := range results {
+		if samePath(key, res.Path, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,321 @@
+// Package landmarks implements the ALT heuristic (A*, landmarks and triangle
+// inequality) by Andrew Goldberg and Chris Harrelson, “Computing the Shortest
+// Path: A* Search Meets Graph Theory”, SODA 2005.
+//
+// A few landmark states are picked and the path costs from and to them are
+// precomputed for every state. By the triangle inequality, for a landmark L
+// the path cost from s to t is at least d(L, t) - d(L, s) and d(s, L) - d(t, L),
+// which makes an admissible and consistent estimate for any pair of states,
+// much better informed than straight line distances on road networks.
+//
+// Landmarks are computed once for a static graph and reused for any number
+// of queries; they can be saved to disk with Save and read back with Load.
+//
+//	l, err := landmarks.Build[int](g, g.Nodes(), 16, landmarks.Farthest, 1)
+//	g.From, g.To = 1, 2
+//	res, err := astar.SearchResult[int](ctx, l.Problem(g, g.To))
+package landmarks
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/pietv/astar"
+)
+
+// Graph is a graph to compute the path costs to and from landmarks on.
+type Graph[S comparable] interface {
+	// Moves from the given state.
+	Neighbors(S) []astar.Edge[S]
+
+	// Moves into the given state: Edge.To is the state the move is made from.
+	Predecessors(S) []astar.Edge[S]
+}
+
+// Selection is a landmark selection strategy.
+type Selection int
+
+const (
+	// Farthest picks every next landmark as far from the picked ones
+	// as possible, starting from a random state (default).
+	Farthest Selection = iota
+
+	// Random picks random states.
+	Random
+)
+
+// Landmarks holds precomputed path costs from and to landmark states.
+type Landmarks[S comparable] struct {
+	// Landmark states.
+	Landmarks []S
+
+	// For every state, the path costs from and to each landmark
+	// interleaved, +Inf if there is no path.
+	dist map[S][]float64
+}
+
+// Build picks k landmarks among the given states of g and computes the path
+// costs from and to them, with 2×k runs of Dijkstra's algorithm. States not
+// in the list but reachable from or to the landmarks get the path costs too.
+// The seed makes the selection repeatable.
+func Build[S comparable](g Graph[S], states []S, k int, sel Selection, seed int64) (*Landmarks[S], error) {
+	if k <= 0 || k > len(states) {
+		return nil, fmt.Errorf("landmarks: cannot pick %d landmarks among %d states", k, len(states))
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	l := &Landmarks[S]{dist: map[S][]float64{}}
+	add := func(landmark S) {
+		i := len(l.Landmarks)
+		l.Landmarks = append(l.Landmarks, landmark)
+		for _, dir := range []struct {
+			edges  func(S) []astar.Edge[S]
+			offset int
+		}{{g.Neighbors, 0}, {g.Predecessors, 1}} {
+			for s, d := range dijkstra(landmark, dir.edges) {
+				l.row(s, k)[2*i+dir.offset] = d
+			}
+		}
+	}
+
+	switch sel {
+	case Random:
+		for _, i := range r.Perm(len(states))[:k] {
+			add(states[i])
+		}
+	case Farthest:
+		// The first landmark is the farthest one from a random state.
+		var (
+			first = states[r.Intn(len(states))]
+			best  = first
+			far   = 0.0
+		)
+		for s, d := range dijkstra(first, g.Neighbors) {
+			if d > far {
+				best, far = s, d
+			}
+		}
+		add(best)
+
+		// Every next one is the farthest one from the closest landmark.
+		for len(l.Landmarks) < k {
+			var (
+				best  S
+				found bool
+				far   = -1.0
+			)
+			for _, s := range states {
+				row, ok := l.dist[s]
+				if !ok || l.picked(s) {
+					continue
+				}
+				closest := math.Inf(1)
+				for i := range l.Landmarks {
+					closest = math.Min(closest, row[2*i])
+				}
+				if !math.IsInf(closest, 1) && closest > far {
+					best, far, found = s, closest, true
+				}
+			}
+			if !found {
+				// The rest is unreachable from the landmarks.
+				for _, s := range states {
+					if !l.picked(s) {
+						best = s
+						break
+					}
+				}
+			}
+			add(best)
+		}
+	default:
+		return nil, fmt.Errorf("landmarks: unknown selection %d", sel)
+	}
+	return l, nil
+}
+
+// row returns the path costs of s, allocating them as needed.
+func (l *Landmarks[S]) row(s S, k int) []float64 {
+	row, ok := l.dist[s]
+	if !ok {
+		row = make([]float64, 2*k)
+		for i := range row {
+			row[i] = math.Inf(1)
+		}
+		l.dist[s] = row
+	}
+	return row
+}
+
+func (l *Landmarks[S]) picked(s S) bool {
+	for _, landmark := range l.Landmarks {
+		if landmark == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Estimate returns the lower bound of the path cost from one state
+// to another, +Inf if there is no path for sure. It is 0 for states
+// the costs have not been computed for.
+func (l *Landmarks[S]) Estimate(from, to S) float64 {
+	s, ok := l.dist[from]
+	if !ok {
+		return 0
+	}
+	t, ok := l.dist[to]
+	if !ok {
+		return 0
+	}
+
+	bound := 0.0
+	for i := range l.Landmarks {
+		fromL, toL := s[2*i], t[2*i]
+		switch {
+		case math.IsInf(fromL, 1):
+		case math.IsInf(toL, 1):
+			// L reaches from, but not to.
+			return math.Inf(1)
+		default:
+			// d(L, to) - d(L, from)
+			bound = math.Max(bound, toL-fromL)
+		}
+
+		fromR, toR := s[2*i+1], t[2*i+1]
+		switch {
+		case math.IsInf(toR, 1):
+		case math.IsInf(fromR, 1):
+			// To reaches L, but from does not.
+			return math.Inf(1)
+		default:
+			// d(from, L) - d(to, L)
+			bound = math.Max(bound, fromR-toR)
+		}
+	}
+	return bound
+}
+
+// Problem returns p with the heuristic estimate of the path cost to goal
+// being the larger of the landmark Estimate and p's own Heuristic.
+// The estimate of the states that can't reach goal may be +Inf, which
+// astar.BucketQueue and astar.RadixHeap frontiers don't accept.
+func (l *Landmarks[S]) Problem(p astar.Problem[S], goal S) astar.Problem[S] {
+	return &problem[S]{Problem: p, l: l, goal: goal}
+}
+
+type problem[S comparable] struct {
+	astar.Problem[S]
+	l    *Landmarks[S]
+	goal S
+}
+
+func (p *problem[S]) Heuristic(s S) float64 {
+	return math.Max(p.Problem.Heuristic(s), p.l.Estimate(s, p.goal))
+}
+
+// file is the on-disk form of Landmarks.
+type file[S comparable] struct {
+	Version   int
+	Landmarks []S
+	Dist      map[S][]float64
+}
+
+// Save writes l to w with encoding/gob. States must be encodable by gob.
+func (l *Landmarks[S]) Save(w io.Writer) error {
+	err := gob.NewEncoder(w).Encode(file[S]{Version: 1, Landmarks: l.Landmarks, Dist: l.dist})
+	if err != nil {
+		return fmt.Errorf("landmarks: %w", err)
+	}
+	return nil
+}
+
+// Load reads landmarks written by Save.
+func Load[S comparable](r io.Reader) (*Landmarks[S], error) {
+	var f file[S]
+	if err := gob.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("landmarks: %w", err)
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("landmarks: unknown version %d", f.Version)
+	}
+	for s, row := range f.Dist {
+		if len(row) != 2*len(f.Landmarks) {
+			return nil, fmt.Errorf("landmarks: state %v has %d path costs, expected %d", s, len(row), 2*len(f.Landmarks))
+		}
+	}
+	return &Landmarks[S]{Landmarks: f.Landmarks, dist: f.Dist}, nil
+}
+
+// SaveFile writes l to the named file.
+func (l *Landmarks[S]) SaveFile(filename string) error {
+	out, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := l.Save(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// LoadFile reads landmarks from the named file.
+func LoadFile[S comparable](filename string) (*Landmarks[S], error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	return Load[S](in)
+}
+
+// dijkstra returns the path costs from start to every state reachable
+// with the given moves.
+func dijkstra[S comparable](start S, edges func(S) []astar.Edge[S]) map[S]float64 {
+	dist := map[S]float64{start: 0}
+	done := map[S]bool{}
+
+	q := &queue[S]{{start, 0}}
+	for q.Len() > 0 {
+		curr := heap.Pop(q).(item[S])
+		if done[curr.s] {
+			continue
+		}
+		done[curr.s] = true
+
+		for _, e := range edges(curr.s) {
+			d := curr.d + e.Cost
+			if old, ok := dist[e.To]; !ok || d < old {
+				dist[e.To] = d
+				heap.Push(q, item[S]{e.To, d})
+			}
+		}
+	}
+	return dist
+}
+
+type item[S comparable] struct {
+	s S
+	d float64
+}
+
+// queue is a min-heap of items with lazy deletion.
+type queue[S comparable] []item[S]
+
+func (q queue[S]) Len() int            { return len(q) }
+func (q queue[S]) Less(i, j int) bool  { return q[i].d < q[j].d }
+func (q queue[S]) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue[S]) Push(x interface{}) { *q = append(*q, x.(item[S])) }
+func (q *queue[S]) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,174 @@
+package landmarks_test
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/graph"
+	"github.com/pietv/astar/grid"
+	. "github.com/pietv/astar/landmarks"
+)
+
+// newGraph returns a random directed graph without coordinates.
+func newGraph(n, m int, r *rand.Rand) (*graph.Graph[int], []int) {
+	g := graph.New[int](true)
+	nodes := []int{}
+	for i := 0; i < n; i++ {
+		g.AddNode(i)
+		nodes = append(nodes, i)
+	}
+	for i := 0; i < m; i++ {
+		g.AddEdge(r.Intn(n), r.Intn(n), float64(1+r.Intn(20)))
+	}
+	return g, nodes
+}
+
+func TestBuild(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		g, nodes := newGraph(100, 400, r)
+		l, err := Build[int](g, nodes, 4, Selection(i%2), int64(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(l.Landmarks) != 4 {
+			t.Fatalf("#%d: got %d landmarks", i, len(l.Landmarks))
+		}
+
+		g.From, g.To = r.Intn(100), r.Intn(100)
+		p := l.Problem(g, g.To)
+
+		violations, err := astar.Verify[int](p, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(violations) > 0 {
+			t.Fatalf("#%d: %v", i, violations[0])
+		}
+
+		plain, err := astar.SearchResult[int](context.Background(), g)
+		alt, altErr := astar.SearchResult[int](context.Background(), p)
+		if (err == nil) != (altErr == nil) {
+			t.Fatalf("#%d: Dijkstra %v, ALT %v", i, err, altErr)
+		}
+		if err != nil {
+			continue
+		}
+		if plain.Cost != alt.Cost || alt.Expanded > plain.Expanded {
+			t.Errorf("#%d: Dijkstra cost %v in %d expansions, ALT %v in %d",
+				i, plain.Cost, plain.Expanded, alt.Cost, alt.Expanded)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g := grid.New(40, 40)
+	g.Connectivity = grid.Eight
+	cells := []grid.Point{}
+	for i := 0; i < g.Rows(); i++ {
+		for j := 0; j < g.Cols(); j++ {
+			p := grid.Point{Row: i, Col: j}
+			if r.Intn(4) == 0 {
+				g.SetWall(p)
+			} else {
+				g.SetCost(p, 1+float64(r.Intn(3)))
+			}
+			cells = append(cells, p)
+		}
+	}
+
+	l, err := Build[grid.Point](g, cells, 8, Farthest, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Landmarks are better informed than octile distances on terrain.
+	var plain, alt int
+	for i := 0; i < 50; i++ {
+		g.From, g.To = cells[r.Intn(len(cells))], cells[r.Intn(len(cells))]
+		if !g.Open(g.From) || !g.Open(g.To) {
+			continue
+		}
+
+		res, err := astar.SearchResult[grid.Point](context.Background(), g)
+		if err == astar.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		altRes, err := astar.SearchResult[grid.Point](context.Background(), l.Problem(g, g.To))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(res.Cost-altRes.Cost) > 1e-9 {
+			t.Errorf("#%d: got cost %v, expected %v", i, altRes.Cost, res.Cost)
+		}
+		plain += res.Expanded
+		alt += altRes.Expanded
+	}
+	if alt >= plain {
+		t.Errorf("ALT expanded %d cells, octile distance %d", alt, plain)
+	}
+}
+
+func TestSave(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	g, nodes := newGraph(50, 200, r)
+	l, err := Build[int](g, nodes, 3, Farthest, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := l.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load[int](&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "landmarks")
+	if err := l.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := LoadFile[int](filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range nodes {
+		for _, t2 := range nodes {
+			if e := l.Estimate(s, t2); loaded.Estimate(s, t2) != e || fromFile.Estimate(s, t2) != e {
+				t.Fatalf("estimates from %d to %d differ", s, t2)
+			}
+		}
+	}
+
+	if _, err := Load[string](bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	g, nodes := newGraph(5, 10, rand.New(rand.NewSource(1)))
+	for _, tt := range []struct {
+		k   int
+		sel Selection
+	}{
+		{0, Farthest},
+		{6, Random},
+		{1, Selection(-1)},
+	} {
+		if _, err := Build[int](g, nodes, tt.k, tt.sel, 1); err == nil {
+			t.Errorf("k %d, selection %d: expected an error", tt.k, tt.sel)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package movingai reads the grid pathfinding benchmarks by Nathan Sturtevant,
+// “Benchmarks for Grid-Based Pathfinding”, IEEE TCIAIG 2012,
+// https://movingai.com/benchmarks/, and runs their scenarios.
+//
+// Maps are octile grids: 8-connected, with diagonal moves of cost √2
+// that don't cut wall corners. Of the terrain types, “.”, “G” (ground)
+// and “S” (swamp) are passable, while “@”, “O” (out of bounds),
+// “T” (trees) and “W” (water) are not.
+package movingai
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pietv/astar"
+	"github.com/pietv/astar/grid"
+)
+
+// ReadMap reads a .map file.
+func ReadMap(r io.Reader) (*grid.Grid, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+
+	var height, width int
+	header := map[string]bool{}
+	line := 0
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "map" {
+			break
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("movingai: map line %d: unknown header %q", line, s.Text())
+		}
+
+		header[fields[0]] = true
+		switch fields[0] {
+		case "type":
+			if fields[1] != "octile" {
+				return nil, fmt.Errorf("movingai: map line %d: unsupported type %q", line, fields[1])
+			}
+		case "height", "width":
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("movingai: map line %d: bad %s %q", line, fields[0], fields[1])
+			}
+			if fields[0] == "height" {
+				height = n
+			} else {
+				width = n
+			}
+		default:
+			return nil, fmt.Errorf("movingai: map line %d: unknown header %q", line, s.Text())
+		}
+	}
+	if !header["height"] || !header["width"] {
+		return nil, fmt.Errorf("movingai: map has no height or width")
+	}
+
+	g := grid.New(height, width)
+	g.Connectivity = grid.Eight
+	g.Corners = grid.NoCorners
+
+	row := 0
+	for ; row < height && s.Scan(); row++ {
+		line++
+		cells := strings.TrimRight(s.Text(), "\r")
+		if len(cells) != width {
+			return nil, fmt.Errorf("movingai: map line %d: %d cells, expected %d", line, len(cells), width)
+		}
+		for col := 0; col < width; col++ {
+			switch cells[col] {
+			case '.', 'G', 'S':
+			case '@', 'O', 'T', 'W':
+				g.SetWall(grid.Point{Row: row, Col: col})
+			default:
+				return nil, fmt.Errorf("movingai: map line %d: unknown terrain %q", line, cells[col])
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("movingai: %w", err)
+	}
+	if row < height {
+		return nil, fmt.Errorf("movingai: map has %d rows, expected %d", row, height)
+	}
+	return g, nil
+}
+
+// LoadMap reads the named .map file.
+func LoadMap(filename string) (*grid.Grid, error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	return ReadMap(in)
+}
+
+// Scenario is a single search problem.
+type Scenario struct {
+	// Scenarios of similar path length are grouped in buckets.
+	Bucket int
+
+	// Map file name and size.
+	Map           string
+	Width, Height int
+
+	// Start and goal locations.
+	Start, Goal grid.Point
+
+	// Shortest path cost.
+	Optimal float64
+}
+
+// ReadScenarios reads a .scen file.
+func ReadScenarios(r io.Reader) ([]Scenario, error) {
+	s := bufio.NewScanner(r)
+
+	scenarios := []Scenario{}
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimRight(s.Text(), "\r")
+		if strings.TrimSpace(text) == "" || line == 1 && strings.HasPrefix(text, "version") {
+			continue
+		}
+
+		// Map names may contain spaces, fields are separated with tabs.
+		fields := strings.Split(text, "\t")
+		if len(fields) != 9 {
+			fields = strings.Fields(text)
+		}
+		if len(fields) != 9 {
+			return nil, fmt.Errorf("movingai: scenario line %d: expected 9 fields, got %d", line, len(fields))
+		}
+
+		var (
+			sc   = Scenario{Map: fields[1]}
+			ints = []*int{&sc.Bucket, nil, &sc.Width, &sc.Height, &sc.Start.Col, &sc.Start.Row, &sc.Goal.Col, &sc.Goal.Row}
+			err  error
+		)
+		for i, p := range ints {
+			if p == nil {
+				continue
+			}
+			if *p, err = strconv.Atoi(fields[i]); err != nil {
+				return nil, fmt.Errorf("movingai: scenario line %d: bad number %q", line, fields[i])
+			}
+		}
+		if sc.Optimal, err = strconv.ParseFloat(fields[8], 64); err != nil {
+			return nil, fmt.Errorf("movingai: scenario line %d: bad optimal length %q", line, fields[8])
+		}
+		scenarios = append(scenarios, sc)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("movingai: %w", err)
+	}
+	return scenarios, nil
+}
+
+// LoadScenarios reads the named .scen file.
+func LoadScenarios(filename string) ([]Scenario, error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	return ReadScenarios(in)
+}
+
+// Tolerance is the largest difference between the path cost found
+// and the optimal length of a scenario, which are rounded in .scen files.
+const Tolerance = 1e-3
+
+// Failure is a scenario with no path or the wrong path cost found.
+type Failure struct {
+	Scenario
+	Cost float64
+	Err  error
+}
+
+// Bucket sums up the runs of the scenarios in a bucket.
+type Bucket struct {
+	Bucket    int
+	Scenarios int
+	Failures  []Failure
+
+	// Totals of the scenarios that did not fail.
+	Expanded int
+	Duration time.Duration
+}
+
+// Run searches every scenario on the map returned by maps for its Map name,
+// and checks the path costs against the optimal lengths. It returns the
+// totals by bucket, sorted by bucket number, or an error if a map can't be
+// loaded or a search fails for reasons other than astar.ErrNotFound.
+// The searches don't record explored states unless opts ask for them
+// with astar.LastSteps().
+func Run(ctx context.Context, scenarios []Scenario, maps func(name string) (*grid.Grid, error), opts ...astar.Option) ([]Bucket, error) {
+	opts = append([]astar.Option{astar.LastSteps(0)}, opts...)
+	var (
+		loaded  = map[string]*grid.Grid{}
+		buckets = map[int]*Bucket{}
+	)
+	for _, sc := range scenarios {
+		g, ok := loaded[sc.Map]
+		if !ok {
+			var err error
+			if g, err = maps(sc.Map); err != nil {
+				return nil, err
+			}
+			loaded[sc.Map] = g
+		}
+		if g.Cols() != sc.Width || g.Rows() != sc.Height {
+			return nil, fmt.Errorf("movingai: map %s is %dx%d, scenario expects %dx%d",
+				sc.Map, g.Cols(), g.Rows(), sc.Width, sc.Height)
+		}
+
+		b, ok := buckets[sc.Bucket]
+		if !ok {
+			b = &Bucket{Bucket: sc.Bucket}
+			buckets[sc.Bucket] = b
+		}
+		b.Scenarios++
+
+		g.From, g.To = sc.Start, sc.Goal
+		res, err := astar.SearchResult[grid.Point](ctx, g, opts...)
+		switch {
+		case err == astar.ErrNotFound:
+			b.Failures = append(b.Failures, Failure{Scenario: sc, Err: err})
+			continue
+		case err != nil:
+			return nil, err
+		case math.Abs(res.Cost-sc.Optimal) > Tolerance:
+			b.Failures = append(b.Failures, Failure{Scenario: sc, Cost: res.Cost})
+			continue
+		}
+		b.Expanded += res.Expanded
+		b.Duration += res.Duration
+	}
+
+	out := make([]Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bucket < out[j].Bucket })
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package movingai_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pietv/astar/grid"
+	. "github.com/pietv/astar/movingai"
+)
+
+var arena = `type octile
+height 5
+width 6
+map
+......
+.@@T..
+.@....
+...W..
+OO....
+`
+
+// The fourth scenario has the wrong optimal length, and the last one's
+// goal is out of bounds.
+var scen = "version 1\n" +
+	"0\tarena.map\t6\t5\t0\t0\t0\t0\t0\n" +
+	"1\tarena.map\t6\t5\t0\t0\t5\t4\t" + fmt.Sprintf("%.8f", 7+math.Sqrt2) + "\n" +
+	"1\tarena.map\t6\t5\t0\t0\t2\t2\t6\n" +
+	"1\tarena.map\t6\t5\t0\t0\t2\t2\t4\n" +
+	"2\tarena.map\t6\t5\t0\t0\t0\t4\t3\n"
+
+func TestReadMap(t *testing.T) {
+	g, err := ReadMap(strings.NewReader(arena))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Rows() != 5 || g.Cols() != 6 || g.Connectivity != grid.Eight {
+		t.Errorf("got %dx%d grid, connectivity %d", g.Rows(), g.Cols(), g.Connectivity)
+	}
+
+	walls := 0
+	for i := 0; i < g.Rows(); i++ {
+		for j := 0; j < g.Cols(); j++ {
+			if !g.Passable(i, j) {
+				walls++
+			}
+		}
+	}
+	if walls != 7 {
+		t.Errorf("got %d walls, expected 7", walls)
+	}
+}
+
+func TestRun(t *testing.T) {
+	scenarios, err := ReadScenarios(strings.NewReader(scen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) != 5 {
+		t.Fatalf("got %d scenarios", len(scenarios))
+	}
+	if sc := scenarios[1]; sc.Map != "arena.map" || sc.Start != (grid.Point{Row: 0, Col: 0}) || sc.Goal != (grid.Point{Row: 4, Col: 5}) {
+		t.Errorf("got %+v", sc)
+	}
+
+	maps := func(name string) (*grid.Grid, error) {
+		if name != "arena.map" {
+			t.Fatalf("got map %q", name)
+		}
+		return ReadMap(strings.NewReader(arena))
+	}
+	buckets, err := Run(context.Background(), scenarios, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, b := range buckets {
+		got = append(got, fmt.Sprintf("%d:%d/%d", b.Bucket, b.Scenarios, len(b.Failures)))
+	}
+	if fmt.Sprint(got) != "[0:1/0 1:3/1 2:1/1]" {
+		t.Errorf("got buckets %v", got)
+	}
+	if f := buckets[1].Failures[0]; f.Optimal != 4 || f.Cost != 6 {
+		t.Errorf("got failure %+v", f)
+	}
+	if f := buckets[2].Failures[0]; f.Err == nil {
+		t.Errorf("got failure %+v, expected an error", f)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, m string
+	}{
+		{"type", "type tile\nheight 1\nwidth 1\nmap\n.\n"},
+		{"size", "type octile\nheight 1\nmap\n.\n"},
+		{"width", "type octile\nheight 1\nwidth 2\nmap\n.\n"},
+		{"height", "type octile\nheight 2\nwidth 1\nmap\n.\n"},
+		{"terrain", "type octile\nheight 1\nwidth 1\nmap\n#\n"},
+	} {
+		if _, err := ReadMap(strings.NewReader(tt.m)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if _, err := ReadScenarios(strings.NewReader("version 1\n0\ta.map\t1\t1\t0\t0\t0\n")); err == nil {
+		t.Errorf("expected an error for missing fields")
+	}
+
+	scenarios := []Scenario{{Map: "arena.map", Width: 7, Height: 5}}
+	_, err := Run(context.Background(), scenarios, func(string) (*grid.Grid, error) {
+		return ReadMap(strings.NewReader(arena))
+	})
+	if err == nil {
+		t.Errorf("expected an error for the wrong map size")
+	}
+}
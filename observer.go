@@ -0,0 +1,52 @@
+package astar
+
+import "fmt"
+
+// Event describes a search step seen by an Observer.
+type Event[S comparable] struct {
+	// The state the event is about.
+	State S
+
+	// The state State has been reached from. Zero value for the start state.
+	Parent S
+
+	// Path cost from the start to State via Parent and its heuristic estimate.
+	G, H float64
+}
+
+// Observer receives search events as they happen. Any of the callbacks
+// may be nil. Callbacks are called synchronously, so they slow down the
+// search by as much time as they take.
+type Observer[S comparable] struct {
+	// A state is taken off the priority queue.
+	Expand func(Event[S])
+
+	// A successor state is put on the priority queue.
+	Generate func(Event[S])
+
+	// A state on the priority queue has got a cheaper path cost.
+	Improve func(Event[S])
+
+	// A successor state is skipped because it has been explored already.
+	Skip func(Event[S])
+}
+
+// Observe sets an observer. Its state type must be the same as the one
+// of the problem being solved. For Search(), SearchContext() and Adapt()
+// that is Observer[interface{}].
+func Observe[S comparable](o Observer[S]) Option {
+	return func(c *config) { c.observer = &o }
+}
+
+// observerFor returns the observer set in c for states of type S,
+// or an empty one if there is none.
+func observerFor[S comparable](c *config) (*Observer[S], error) {
+	if c.observer == nil {
+		return &Observer[S]{}, nil
+	}
+	o, ok := c.observer.(*Observer[S])
+	if !ok {
+		return nil, fmt.Errorf("astar: observer %T does not match %T", c.observer, o)
+	}
+	return o, nil
+}
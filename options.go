@@ -0,0 +1,93 @@
+package astar
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded means that the search was stopped by one of the limits
+// set with MaxExpanded(), MaxFrontier() or MaxExplored(). The actual error
+// returned is a *LimitError which wraps ErrLimitExceeded.
+var ErrLimitExceeded = errors.New("search limit exceeded")
+
+// LimitError tells which limit has stopped the search.
+type LimitError struct {
+	// Limit name: “expanded”, “frontier” or “explored”.
+	Limit string
+
+	// Limit value.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: more than %d %s states", ErrLimitExceeded, e.Max, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error { return ErrLimitExceeded }
+
+// Option configures SearchContext() and the other searches accepting options.
+type Option func(*config)
+
+type config struct {
+	// Zero means no limit.
+	maxExpanded, maxFrontier, maxExplored int
+
+	// *Observer[S] for the state type of the search.
+	observer interface{}
+
+	// Number of explored states to record, negative means all.
+	lastSteps int
+
+	// Expected number of states to allocate space for.
+	sizeHint int
+
+	// Put explored states back on the frontier if a cheaper path is found.
+	reopen bool
+
+	// Report inconsistent heuristic estimates.
+	checkConsistency bool
+
+	// TieBreak or func(a, b Node[S]) bool for the state type of the search.
+	tieBreak interface{}
+
+	// Priority queue implementation.
+	frontier Frontier
+
+	// func(S) S telling states apart for the state type of the search.
+	key interface{}
+
+	// Return panics in the problem's methods as a *PanicError.
+	recoverPanics bool
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{lastSteps: -1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// MaxExpanded limits the number of states taken off the priority queue.
+func MaxExpanded(n int) Option { return func(c *config) { c.maxExpanded = n } }
+
+// MaxFrontier limits the number of states waiting in the priority queue.
+func MaxFrontier(n int) Option { return func(c *config) { c.maxFrontier = n } }
+
+// MaxExplored limits the size of the explored set.
+func MaxExplored(n int) Option { return func(c *config) { c.maxExplored = n } }
+
+// LastSteps keeps only the last n explored states instead of all of them.
+// With n = 0, explored states are not recorded at all, which saves memory
+// on long searches when only the path is needed.
+func LastSteps(n int) Option { return func(c *config) { c.lastSteps = n } }
+
+// SizeHint preallocates space for about n states, saving on growing
+// the internal data structures when the search space size is known.
+func SizeHint(n int) Option { return func(c *config) { c.sizeHint = n } }
+
+// Reopen puts an explored state back on the priority queue when a cheaper
+// path to it is found. That is necessary to find the shortest path when
+// Estimate() is admissible, but not consistent, that is when it can drop
+// by more than the Cost() of a move.
+func Reopen() Option { return func(c *config) { c.reopen = true } }
@@ -0,0 +1,199 @@
+package pdb_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/pietv/astar"
+	. "github.com/pietv/astar/pdb"
+)
+
+// puzzle is a sliding-tile puzzle state.
+type puzzle struct {
+	p        Puzzle
+	board    string
+	estimate func([]byte) float64
+}
+
+func (s puzzle) Start() string        { return s.board }
+func (s puzzle) IsGoal(b string) bool { return b == string(s.p.Goal) }
+func (s puzzle) Heuristic(b string) float64 {
+	if s.estimate == nil {
+		return 0
+	}
+	return s.estimate([]byte(b))
+}
+func (s puzzle) Neighbors(b string) []astar.Edge[string] {
+	edges := []astar.Edge[string]{}
+	blank := bytes.IndexByte([]byte(b), 0)
+	for _, to := range s.p.Adjacent[blank] {
+		next := []byte(b)
+		next[blank], next[to] = next[to], next[blank]
+		edges = append(edges, astar.Edge[string]{To: string(next), Cost: 1})
+	}
+	return edges
+}
+
+// shuffle makes random moves from the goal.
+func shuffle(p Puzzle, moves int, r *rand.Rand) []byte {
+	b := append([]byte{}, p.Goal...)
+	blank := bytes.IndexByte(b, 0)
+	for i := 0; i < moves; i++ {
+		to := p.Adjacent[blank][r.Intn(len(p.Adjacent[blank]))]
+		b[blank], b[to] = b[to], b[blank]
+		blank = to
+	}
+	return b
+}
+
+func TestEightPuzzle(t *testing.T) {
+	p := SlidingTile(3, 3)
+
+	// Every move counts and all tiles are in the pattern:
+	// the database has the exact distances.
+	exact, err := Build(p, []byte{1, 2, 3, 4, 5, 6, 7, 8}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	low, err := Build(p, []byte{1, 2, 3, 4}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := Build(p, []byte{5, 6, 7, 8}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := Sum(low, high)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corner, err := Build(p, []byte{1, 2, 4, 5}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	max := Max(sum, corner.Estimate)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		board := shuffle(p, 100, r)
+
+		res, err := astar.SearchResult[string](context.Background(), puzzle{p: p, board: string(board)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := exact.Lookup(board); float64(got) != res.Cost {
+			t.Errorf("%v: got %d moves, expected %v", board, got, res.Cost)
+		}
+
+		for _, tt := range []struct {
+			name     string
+			estimate func([]byte) float64
+		}{
+			{"sum", sum},
+			{"max", max},
+		} {
+			h := puzzle{p: p, board: string(board), estimate: tt.estimate}
+			if v := tt.estimate(board); v > res.Cost {
+				t.Errorf("%v: %s estimate %v exceeds %v moves", board, tt.name, v, res.Cost)
+			}
+			hres, err := astar.SearchResult[string](context.Background(), h)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hres.Cost != res.Cost || hres.Expanded > res.Expanded {
+				t.Errorf("%v: %s got %v moves in %d expansions, expected %v in at most %d",
+					board, tt.name, hres.Cost, hres.Expanded, res.Cost, res.Expanded)
+			}
+		}
+	}
+
+	violations, err := astar.Verify[string](puzzle{p: p, board: string(shuffle(p, 30, r)), estimate: max}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) > 0 {
+		t.Errorf("%v", violations[0])
+	}
+}
+
+func TestSave(t *testing.T) {
+	p := SlidingTile(3, 3)
+	d, err := Build(p, []byte{2, 4, 6}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 9*8*7*6 {
+		t.Errorf("got %d entries", d.Len())
+	}
+
+	var buf bytes.Buffer
+	if err := d.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() > d.Len()+16 {
+		t.Errorf("got %d bytes for %d entries", buf.Len(), d.Len())
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "pdb")
+	if err := d.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := LoadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		board := shuffle(p, 50, r)
+		if loaded.Lookup(board) != d.Lookup(board) || fromFile.Lookup(board) != d.Lookup(board) {
+			t.Fatalf("%v: lookups differ", board)
+		}
+	}
+	if !bytes.Equal(loaded.Tiles, d.Tiles) || !loaded.Additive {
+		t.Errorf("got tiles %v, additive %v", loaded.Tiles, loaded.Additive)
+	}
+
+	many := make([]byte, 32)
+	for i := range many {
+		many[i] = byte(i + 1)
+	}
+	for _, header := range []string{
+		"PDB2\x09\x03",
+		"PDB1\xc8\x03\x01\x02\x03\x01",         // 200 positions.
+		"PDB1\x09\x03\x01\x01\x02\x01",         // Repeated tiles.
+		"PDB1\x09\x02\x00\x01\x01",             // The blank.
+		"PDB1\x09\x02\x01\x02\x01\x05",         // Truncated table.
+		"PDB1\x40\x20" + string(many) + "\x01", // Too large a table.
+	} {
+		if _, err := Load(bytes.NewReader([]byte(header))); err == nil {
+			t.Errorf("%q: expected an error", header)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	p := SlidingTile(3, 3)
+	for _, tiles := range [][]byte{{}, {0}, {9}, {1, 1}, {1, 2, 3, 4, 5, 6, 7, 8, 0}} {
+		if _, err := Build(p, tiles, true); err == nil {
+			t.Errorf("%v: expected an error", tiles)
+		}
+	}
+
+	a, _ := Build(p, []byte{1, 2}, true)
+	b, _ := Build(p, []byte{2, 3}, true)
+	c, _ := Build(p, []byte{4, 5}, false)
+	if _, err := Sum(a, b); err == nil {
+		t.Errorf("expected an error for overlapping patterns")
+	}
+	if _, err := Sum(a, c); err == nil {
+		t.Errorf("expected an error for a database that is not additive")
+	}
+}
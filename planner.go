@@ -0,0 +1,257 @@
+package astar
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"time"
+)
+
+// Dynamic is a graph with changing move costs for a Planner.
+type Dynamic[S comparable] interface {
+	// Available moves from the given state with their current costs.
+	Neighbors(S) []Edge[S]
+
+	// Moves into the given state with their current costs: Edge.To is
+	// the state the move is made from.
+	Predecessors(S) []Edge[S]
+
+	// Consistent heuristic estimate of the path cost between two states.
+	Distance(from, to S) float64
+}
+
+// Planner finds the shortest path to a goal and keeps it up to date as move
+// costs change and the agent following the path moves. It implements
+// the D* Lite algorithm by Sven Koenig and Maxim Likhachev, which searches
+// backwards from the goal and keeps the search results between the calls
+// of Plan(), so that replanning only re-explores the states whose path costs
+// have been affected by the changes.
+//
+// A Planner is not safe for concurrent use.
+type Planner[S comparable] struct {
+	g           Dynamic[S]
+	start, goal S
+
+	// Last state the agent has been moved from, and the accumulated
+	// estimate correction for the moves made since the search has started.
+	last S
+	km   float64
+
+	nodes map[S]*plannerNode[S]
+	queue plannerQueue[S]
+}
+
+// plannerNode is a state with its path cost to the goal (g) and
+// its one-step lookahead value (rhs).
+type plannerNode[S comparable] struct {
+	state  S
+	g, rhs float64
+
+	// Priority queue key and index, -1 when not queued.
+	key   [2]float64
+	index int
+}
+
+// NewPlanner returns a Planner for the shortest path from start to goal in g.
+func NewPlanner[S comparable](g Dynamic[S], start, goal S) *Planner[S] {
+	p := &Planner[S]{
+		g:     g,
+		start: start,
+		goal:  goal,
+		last:  start,
+		nodes: map[S]*plannerNode[S]{},
+	}
+
+	n := p.node(goal)
+	n.rhs = 0
+	p.update(n)
+	return p
+}
+
+// Move tells the Planner that the agent has moved to s. The next path
+// returned by Plan() starts at s.
+func (p *Planner[S]) Move(s S) {
+	p.km += p.g.Distance(p.last, s)
+	p.last, p.start = s, s
+}
+
+// UpdateEdges tells the Planner that moves out of the given states have
+// changed: got different costs, appeared or disappeared. The changes
+// are taken into account by the next call of Plan().
+func (p *Planner[S]) UpdateEdges(states ...S) {
+	for _, s := range states {
+		p.lookahead(p.node(s))
+	}
+}
+
+// Plan returns the shortest path from the current state of the agent
+// to the goal. The Result's Steps, Expanded and Duration are those of
+// this call. ErrNotFound is returned if the goal is not reachable,
+// ctx.Err() if ctx is done before the path is found. A panic in one
+// of the graph's methods is returned as a *PanicError. States are
+// compared with ==: ErrKeysUnsupported is returned if the graph is
+// a Keyer.
+func (p *Planner[S]) Plan(ctx context.Context) (res *Result[S], err error) {
+	res = &Result[S]{Steps: []S{}}
+	begin := time.Now()
+	defer func() { res.Duration = time.Since(begin) }()
+
+	if _, ok := p.g.(Keyer[S]); ok {
+		return res, ErrKeysUnsupported
+	}
+
+	// Return panics in the graph's methods as errors.
+	g := newDynamicGuard(p.g)
+	defer func(d Dynamic[S]) { p.g = d }(p.g)
+	p.g = g
+	defer g.catch(&err)
+
+	done := ctx.Done()
+	start := p.node(p.start)
+
+	for len(p.queue) > 0 && (less(p.queue[0].key, p.key(start)) || start.rhs != start.g) {
+		select {
+		case <-done:
+			return res, ctx.Err()
+		default:
+		}
+
+		u := p.queue[0]
+		res.Expanded++
+		res.Steps = append(res.Steps, u.state)
+
+		switch key := p.key(u); {
+		case less(u.key, key):
+			// Outdated key.
+			u.key = key
+			heap.Fix(&p.queue, u.index)
+		case u.g > u.rhs:
+			// Overconsistent: the path cost has decreased.
+			u.g = u.rhs
+			heap.Remove(&p.queue, u.index)
+			for _, edge := range p.g.Predecessors(u.state) {
+				if s := p.node(edge.To); edge.Cost+u.g < s.rhs && s.state != p.goal {
+					s.rhs = edge.Cost + u.g
+					p.update(s)
+				}
+			}
+		default:
+			// Underconsistent: the path cost has increased.
+			g := u.g
+			u.g = math.Inf(1)
+			p.lookahead(u)
+			for _, edge := range p.g.Predecessors(u.state) {
+				if s := p.node(edge.To); s.rhs == edge.Cost+g {
+					p.lookahead(s)
+				}
+			}
+		}
+		if len(p.queue) > res.MaxFrontier {
+			res.MaxFrontier = len(p.queue)
+		}
+	}
+
+	if math.IsInf(start.g, 1) {
+		return res, ErrNotFound
+	}
+
+	// Follow the cheapest moves from the start to the goal.
+	for curr, cost := start, 0.0; ; {
+		res.Path = append(res.Path, curr.state)
+		h := p.g.Distance(curr.state, p.goal)
+		res.Nodes = append(res.Nodes, Node[S]{curr.state, cost, h, cost + h})
+		if curr.state == p.goal {
+			break
+		}
+		if len(res.Path) > len(p.nodes) {
+			// Shouldn't happen, but don't go in circles.
+			return res, ErrNotFound
+		}
+
+		next, min, step := curr, math.Inf(1), 0.0
+		for _, edge := range p.g.Neighbors(curr.state) {
+			if n, ok := p.nodes[edge.To]; ok && edge.Cost+n.g < min {
+				next, min, step = n, edge.Cost+n.g, edge.Cost
+			}
+		}
+		if next == curr {
+			return res, ErrNotFound
+		}
+		curr, cost = next, cost+step
+	}
+	res.Cost = start.g
+
+	return res, nil
+}
+
+// node returns the search node of s, creating it if necessary.
+func (p *Planner[S]) node(s S) *plannerNode[S] {
+	n, ok := p.nodes[s]
+	if !ok {
+		n = &plannerNode[S]{state: s, g: math.Inf(1), rhs: math.Inf(1), index: -1}
+		p.nodes[s] = n
+	}
+	return n
+}
+
+func (p *Planner[S]) key(n *plannerNode[S]) [2]float64 {
+	m := math.Min(n.g, n.rhs)
+	return [2]float64{m + p.g.Distance(p.start, n.state) + p.km, m}
+}
+
+// lookahead recalculates the rhs value of n from its successors.
+func (p *Planner[S]) lookahead(n *plannerNode[S]) {
+	if n.state != p.goal {
+		n.rhs = math.Inf(1)
+		for _, edge := range p.g.Neighbors(n.state) {
+			if succ, ok := p.nodes[edge.To]; ok && edge.Cost+succ.g < n.rhs {
+				n.rhs = edge.Cost + succ.g
+			}
+		}
+	}
+	p.update(n)
+}
+
+// update puts n on the priority queue if its g and rhs values differ,
+// and takes it off otherwise.
+func (p *Planner[S]) update(n *plannerNode[S]) {
+	switch {
+	case n.g != n.rhs && n.index >= 0:
+		n.key = p.key(n)
+		heap.Fix(&p.queue, n.index)
+	case n.g != n.rhs:
+		n.key = p.key(n)
+		heap.Push(&p.queue, n)
+	case n.index >= 0:
+		heap.Remove(&p.queue, n.index)
+	}
+}
+
+// less compares keys lexicographically.
+func less(a, b [2]float64) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+type plannerQueue[S comparable] []*plannerNode[S]
+
+func (q plannerQueue[S]) Len() int           { return len(q) }
+func (q plannerQueue[S]) Less(i, j int) bool { return less(q[i].key, q[j].key) }
+func (q plannerQueue[S]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *plannerQueue[S]) Push(x interface{}) {
+	n := x.(*plannerNode[S])
+	n.index = len(*q)
+	*q = append(*q, n)
+}
+
+func (q *plannerQueue[S]) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	n.index = -1
+	*q = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,113 @@
+package astar
+
+import "context"
+
+// Edge is a move to a neighbor state along with its path cost.
+type Edge[S comparable] struct {
+	To   S
+	Cost float64
+}
+
+// Problem describes a search space with pure functions of a state.
+// Unlike Interface, it has no current state and nothing is changed during
+// the search, so a single Problem value may be shared by concurrent searches
+// as long as its methods are safe for concurrent use.
+type Problem[S comparable] interface {
+	// Initial state.
+	Start() S
+
+	// Is the given state final?
+	IsGoal(S) bool
+
+	// Available moves from the given state with their path costs.
+	Neighbors(S) []Edge[S]
+
+	// Heuristic estimate of “how far to go?” between the given
+	// and the final state. Smaller values mean closer.
+	Heuristic(S) float64
+}
+
+// SearchProblem is the same as SearchContext for a Problem.
+func SearchProblem[S comparable](ctx context.Context, p Problem[S], opts ...Option) ([]S, []S, error) {
+	res, err := search(ctx, p, newContextConfig(opts))
+	return res.Path, res.Steps, err
+}
+
+// Adapt turns an Interface into a Problem by moving p to the given state
+// before asking for its successors and their costs. The resulting Problem
+// shares p, so it is not safe for concurrent use. If p is a Keyer, the search
+// uses its keys.
+func Adapt(p Interface) Problem[interface{}] {
+	return &adapter[interface{}]{p: p}
+}
+
+// AdaptG is the same as Adapt for an InterfaceG.
+func AdaptG[S comparable](p InterfaceG[S]) Problem[S] {
+	return &adapter[S]{p: p}
+}
+
+type adapter[S comparable] struct {
+	p InterfaceG[S]
+
+	// State p has been moved to last and its key.
+	curr    S
+	currKey interface{}
+	moved   bool
+
+	// Tells states apart, nil to compare them with ==.
+	key func(S) interface{}
+
+	// The method of p called last and its state for a PanicError.
+	callback string
+	state    S
+}
+
+func (a *adapter[S]) call(callback string, s S) {
+	a.callback, a.state = callback, s
+}
+
+func (a *adapter[S]) move(s S) {
+	if a.key == nil {
+		if a.moved && a.curr == s {
+			return
+		}
+	} else {
+		a.call("Key", s)
+		k := a.key(s)
+		if a.moved && a.currKey == k {
+			return
+		}
+		a.currKey = k
+	}
+	a.call("Move", s)
+	a.p.Move(s)
+	a.curr, a.moved = s, true
+}
+
+func (a *adapter[S]) Start() S {
+	a.callback = "Start"
+	return a.p.Start()
+}
+
+func (a *adapter[S]) IsGoal(s S) bool {
+	a.move(s)
+	a.call("Finish", s)
+	return a.p.Finish()
+}
+
+func (a *adapter[S]) Neighbors(s S) []Edge[S] {
+	a.move(s)
+	a.call("Successors", s)
+	succ := a.p.Successors()
+	edges := make([]Edge[S], len(succ))
+	for i, to := range succ {
+		a.call("Cost", to)
+		edges[i] = Edge[S]{to, a.p.Cost(to)}
+	}
+	return edges
+}
+
+func (a *adapter[S]) Heuristic(s S) float64 {
+	a.call("Estimate", s)
+	return a.p.Estimate(s)
+}
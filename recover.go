@@ -0,0 +1,281 @@
+package astar
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// PanicError is a panic in one of the problem's methods, recovered by
+// the search and returned instead (see RecoverPanics()).
+type PanicError struct {
+	// Method that has panicked: “Start”, “Move”, “Successors”, “Cost”,
+	// “Estimate”, “Finish” or “Key” for an Interface, and “Start”,
+	// “IsGoal”, “Neighbors”, “Heuristic”, “Goal”, “Predecessors”,
+	// “HeuristicFromStart”, “Distance” or “Key” for the other problems.
+	Callback string
+
+	// The state the method has been called for, nil for Start() and Goal().
+	// That is the state moved to for Move(), Successors() and Finish(),
+	// the successor state for Cost(), and the second state for Distance().
+	State interface{}
+
+	// Value passed to panic().
+	Value interface{}
+
+	// Stack trace of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	if e.Callback == "Start" || e.Callback == "Goal" {
+		return fmt.Sprintf("astar: %s() panicked: %v\n%s", e.Callback, e.Value, e.Stack)
+	}
+	return fmt.Sprintf("astar: %s() panicked at state %v: %v\n%s", e.Callback, e.State, e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, such as
+// a *runtime.TypeAssertionError.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// UncomparableError means that a problem's method has returned a state
+// which can't be compared with ==, such as a slice in an interface{},
+// so the search can't keep track of it. Such states need a Keyer or
+// the Key() option. Searches recovering panics (see RecoverPanics())
+// return an UncomparableError instead of panicking.
+type UncomparableError struct {
+	// Method that has returned the state, “Key” if it is a key.
+	Callback string
+
+	// The state or the key.
+	State interface{}
+}
+
+func (e *UncomparableError) Error() string {
+	return fmt.Sprintf("astar: %s() returned %v of uncomparable type %T, which needs a Keyer or Key()", e.Callback, e.State, e.State)
+}
+
+// RecoverPanics makes the search recover panics in the problem's methods
+// and return them as a *PanicError, so that a faulty problem doesn't crash
+// the whole program. Uncomparable states are returned as
+// an *UncomparableError. Panics elsewhere, such as in Observe() callbacks,
+// are not recovered.
+//
+// Recovery is on by default in the functions taking a context: SearchContext,
+// SearchContextG, SearchProblem, SearchResult, IDAStarContext, IDAStarResult,
+// KShortestPaths and SearchBidirectional, and always in SearchAnytime and
+// Planner.Plan. It is off in Search, SearchG, IDAStar and KShortest.
+func RecoverPanics(on bool) Option {
+	return func(c *config) { c.recoverPanics = on }
+}
+
+// newContextConfig is newConfig for the search functions taking a context,
+// which recover panics unless told otherwise.
+func newContextConfig(opts []Option) *config {
+	return newConfig(append([]Option{RecoverPanics(true)}, opts...))
+}
+
+// guard is a Problem, a Reversible or a Dynamic recording which of
+// the methods of the one it wraps is being called and for what state,
+// so that a panic in it can be told from a panic in the search itself.
+// It also checks that the states returned can be compared.
+type guard[S comparable] struct {
+	p Problem[S]
+	r Reversible[S]
+	d Dynamic[S]
+
+	// Check the states returned: S may hold uncomparable values.
+	check bool
+
+	// Method being called, empty between calls.
+	callback string
+	state    S
+}
+
+// newGuard wraps a Problem, which may also be a Reversible.
+func newGuard[S comparable](p Problem[S]) *guard[S] {
+	g := &guard[S]{p: p, check: mayBeUncomparable[S]()}
+	g.r, _ = p.(Reversible[S])
+	return g
+}
+
+// newDynamicGuard wraps a Dynamic.
+func newDynamicGuard[S comparable](d Dynamic[S]) *guard[S] {
+	return &guard[S]{d: d, check: mayBeUncomparable[S]()}
+}
+
+func (g *guard[S]) enter(callback string, s S) { g.callback, g.state = callback, s }
+func (g *guard[S]) leave()                     { g.callback = "" }
+
+// returned checks a state returned by callback.
+func (g *guard[S]) returned(callback string, s S) {
+	if g.check && !reflect.ValueOf(&s).Elem().Comparable() {
+		if _, ok := g.p.(*adapter[S]); ok && callback == "Neighbors" {
+			callback = "Successors"
+		}
+		panic(&UncomparableError{callback, s})
+	}
+}
+
+func (g *guard[S]) Start() S {
+	var zero S
+	g.enter("Start", zero)
+	s := g.p.Start()
+	g.leave()
+	g.returned("Start", s)
+	return s
+}
+
+func (g *guard[S]) Goal() S {
+	var zero S
+	g.enter("Goal", zero)
+	s := g.r.Goal()
+	g.leave()
+	g.returned("Goal", s)
+	return s
+}
+
+func (g *guard[S]) IsGoal(s S) bool {
+	g.enter("IsGoal", s)
+	goal := g.p.IsGoal(s)
+	g.leave()
+	return goal
+}
+
+func (g *guard[S]) Neighbors(s S) []Edge[S] {
+	g.enter("Neighbors", s)
+	var edges []Edge[S]
+	if g.d != nil {
+		edges = g.d.Neighbors(s)
+	} else {
+		edges = g.p.Neighbors(s)
+	}
+	g.leave()
+	for _, edge := range edges {
+		g.returned("Neighbors", edge.To)
+	}
+	return edges
+}
+
+func (g *guard[S]) Predecessors(s S) []Edge[S] {
+	g.enter("Predecessors", s)
+	var edges []Edge[S]
+	if g.d != nil {
+		edges = g.d.Predecessors(s)
+	} else {
+		edges = g.r.Predecessors(s)
+	}
+	g.leave()
+	for _, edge := range edges {
+		g.returned("Predecessors", edge.To)
+	}
+	return edges
+}
+
+func (g *guard[S]) Heuristic(s S) float64 {
+	g.enter("Heuristic", s)
+	h := g.p.Heuristic(s)
+	g.leave()
+	return h
+}
+
+func (g *guard[S]) HeuristicFromStart(s S) float64 {
+	g.enter("HeuristicFromStart", s)
+	h := g.r.HeuristicFromStart(s)
+	g.leave()
+	return h
+}
+
+func (g *guard[S]) Distance(from, to S) float64 {
+	g.enter("Distance", to)
+	d := g.d.Distance(from, to)
+	g.leave()
+	return d
+}
+
+// keys guards a key function given with Key() or a Keyer. The keys
+// are checked rather than the states, which may be uncomparable.
+func (g *guard[S]) keys(key func(S) interface{}) func(S) interface{} {
+	g.check = false
+	return func(s S) interface{} {
+		g.enter("Key", s)
+		k := key(s)
+		g.leave()
+		if k != nil && !reflect.ValueOf(k).Comparable() {
+			panic(&UncomparableError{"Key", k})
+		}
+		return k
+	}
+}
+
+// catch is deferred by the searches to return a panic in one of
+// the guarded methods as *err. Other panics are let through.
+func (g *guard[S]) catch(err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if e, ok := v.(*UncomparableError); ok {
+		*err = e
+		return
+	}
+	if g.callback == "" {
+		panic(v)
+	}
+
+	e := &PanicError{Callback: g.callback, State: g.state, Value: v, Stack: debug.Stack()}
+
+	// A guard wrapped by g, such as the one of KShortestPaths around
+	// the problems of its searches, knows better which method has panicked,
+	// and an adapter knows which of the Interface methods it has been.
+	p := g.p
+	for p != nil {
+		switch q := p.(type) {
+		case *guard[S]:
+			if q.callback == "" {
+				p = nil
+				continue
+			}
+			e.Callback, e.State = q.callback, q.state
+			p = q.p
+		case *restricted[S]:
+			p = q.Problem
+		case *adapter[S]:
+			if q.callback != "" && e.Callback != "Key" {
+				e.Callback, e.State = q.callback, q.state
+			}
+			p = nil
+		default:
+			p = nil
+		}
+	}
+	if e.Callback == "Start" || e.Callback == "Goal" {
+		e.State = nil
+	}
+	*err = e
+}
+
+// mayBeUncomparable tells whether values of type S can fail to compare
+// with ==, that is whether S is or contains an interface type.
+func mayBeUncomparable[S comparable]() bool {
+	var hasInterface func(t reflect.Type) bool
+	hasInterface = func(t reflect.Type) bool {
+		switch t.Kind() {
+		case reflect.Interface:
+			return true
+		case reflect.Array:
+			return hasInterface(t.Elem())
+		case reflect.Struct:
+			for i := 0; i < t.NumField(); i++ {
+				if hasInterface(t.Field(i).Type) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return hasInterface(reflect.TypeOf((*S)(nil)).Elem())
+}
@@ -0,0 +1,57 @@
+package astar
+
+import (
+	"context"
+	"time"
+)
+
+// Node is a state on the path along with its path cost from the start (G),
+// heuristic estimate (H) and their sum (F).
+type Node[S comparable] struct {
+	State   S
+	G, H, F float64
+}
+
+// Result is the outcome of SearchResult(): the path found and search statistics.
+type Result[S comparable] struct {
+	// The shortest path from start to finish, nil if not found.
+	Path []S
+
+	// Path states with their G, H and F values.
+	Nodes []Node[S]
+
+	// Path cost, that is G of the final state.
+	Cost float64
+
+	// States in the order they have been explored,
+	// only the last ones if LastSteps() is set.
+	Steps []S
+
+	// Number of states taken off the priority queue.
+	Expanded int
+
+	// Number of states put on the priority queue, including the start state.
+	Generated int
+
+	// Number of times a state on the priority queue got a cheaper path cost.
+	Updated int
+
+	// Number of explored states put back on the priority queue (see Reopen()).
+	Reopened int
+
+	// Peak priority queue size.
+	MaxFrontier int
+
+	// Heuristic consistency violations if CheckConsistency() is set.
+	Violations []Violation[S]
+
+	// Wall-clock search time.
+	Duration time.Duration
+}
+
+// SearchResult is the same as SearchProblem, but returns a Result.
+// The Result is returned even when the search fails, with the statistics
+// and states explored so far.
+func SearchResult[S comparable](ctx context.Context, p Problem[S], opts ...Option) (*Result[S], error) {
+	return search(ctx, p, newContextConfig(opts))
+}
@@ -0,0 +1,70 @@
+package astar
+
+import "fmt"
+
+// TieBreak is a policy of choosing between states with equal
+// Cost() + Estimate() values on the priority queue.
+type TieBreak int
+
+const (
+	// AnyTie leaves the order to the priority queue (default).
+	AnyTie TieBreak = iota
+
+	// HigherG prefers states with a higher path cost,
+	// that is the ones further along the way.
+	HigherG
+
+	// LowerH prefers states with a lower heuristic estimate,
+	// that is the ones closer to the finish.
+	LowerH
+
+	// LIFO prefers states put on the priority queue last.
+	LIFO
+
+	// FIFO prefers states put on the priority queue first.
+	FIFO
+)
+
+// TieBreaking sets a tie-breaking policy. On maps with many equal-cost
+// paths, HigherG, LowerH and LIFO usually explore fewer states.
+// Any policy other than AnyTie makes the explored states reproducible
+// for the same sequence of successors.
+func TieBreaking(t TieBreak) Option {
+	return func(c *config) { c.tieBreak = t }
+}
+
+// TieBreakFunc sets a custom tie-breaking policy: less reports whether
+// a should be explored before b. Its state type must be the same as the one
+// of the problem being solved.
+func TieBreakFunc[S comparable](less func(a, b Node[S]) bool) Option {
+	return func(c *config) { c.tieBreak = less }
+}
+
+// tieBreakerFor returns the tie-breaking function set in c for states
+// of type S, or nil if there is none.
+func tieBreakerFor[S comparable](c *config) (func(a, b *state[S]) bool, error) {
+	switch t := c.tieBreak.(type) {
+	case nil:
+		return nil, nil
+	case TieBreak:
+		switch t {
+		case AnyTie:
+			return nil, nil
+		case HigherG:
+			return func(a, b *state[S]) bool { return a.cost > b.cost }, nil
+		case LowerH:
+			return func(a, b *state[S]) bool { return a.estimate < b.estimate }, nil
+		case LIFO:
+			return func(a, b *state[S]) bool { return a.seq > b.seq }, nil
+		case FIFO:
+			return func(a, b *state[S]) bool { return a.seq < b.seq }, nil
+		}
+		return nil, fmt.Errorf("astar: unknown tie-breaking policy %d", t)
+	case func(a, b Node[S]) bool:
+		node := func(s *state[S]) Node[S] {
+			return Node[S]{s.state, s.cost, s.estimate, s.cost + s.estimate}
+		}
+		return func(a, b *state[S]) bool { return t(node(a), node(b)) }, nil
+	}
+	return nil, fmt.Errorf("astar: tie-breaking function %T does not match %T", c.tieBreak, func(a, b Node[S]) bool { return false })
+}
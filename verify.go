@@ -0,0 +1,158 @@
+package astar
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Tolerance for floating point comparisons of heuristic estimates.
+const epsilon = 1e-9
+
+// ViolationKind tells which property of a heuristic is violated.
+type ViolationKind int
+
+const (
+	// Inconsistent: h(n) > c(n, n') + h(n') for a move from n to n'.
+	Inconsistent ViolationKind = iota
+
+	// Inadmissible: h(n) is greater than the actual cost from n to the goal.
+	Inadmissible
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case Inconsistent:
+		return "inconsistent"
+	case Inadmissible:
+		return "inadmissible"
+	}
+	return fmt.Sprintf("ViolationKind(%d)", int(k))
+}
+
+// Violation is a heuristic estimate found to be too large.
+type Violation[S comparable] struct {
+	Kind ViolationKind
+
+	// The state with the estimate that is too large.
+	State S
+
+	// The successor state the estimate was compared with
+	// (Inconsistent only).
+	Next S
+
+	// The estimate and by how much it exceeds the bound.
+	H, Excess float64
+}
+
+func (v Violation[S]) String() string {
+	if v.Kind == Inconsistent {
+		return fmt.Sprintf("%v: h(%v) = %v exceeds c + h(%v) by %v", v.Kind, v.State, v.H, v.Next, v.Excess)
+	}
+	return fmt.Sprintf("%v: h(%v) = %v exceeds the actual cost by %v", v.Kind, v.State, v.H, v.Excess)
+}
+
+// CheckConsistency makes the search check every move from an explored state
+// for consistency of the heuristic estimate: h(n) ≤ c(n, n') + h(n').
+// Violations are reported in Result.Violations. Intended for debugging,
+// as it costs an extra Heuristic() call per move.
+func CheckConsistency() Option { return func(c *config) { c.checkConsistency = true } }
+
+// Verify checks the heuristic of p for consistency on every move and for
+// admissibility against the actual costs to the goal, which it finds with
+// a backward Dijkstra search. It enumerates all the states reachable from
+// p.Start(), so it is meant for small instances. If there are more than
+// maxStates of them, Verify returns a *LimitError along with
+// the consistency violations found so far. If p is a Keyer,
+// states are told apart by their keys.
+func Verify[S comparable](p Problem[S], maxStates int) ([]Violation[S], error) {
+	violations := []Violation[S]{}
+
+	key, err := keyFor[S](&config{}, p)
+	if err != nil {
+		return violations, err
+	}
+	useKey(p, key)
+
+	type move struct {
+		from S
+		cost float64
+	}
+
+	// Reachable states with their estimates and incoming moves, by key.
+	estimates := newStateMap[S, float64](key, 0)
+	predecessors := newStateMap[S, []move](key, 0)
+	goals := []S{}
+
+	// Reachable states in the order they have been reached.
+	reached := []S{}
+
+	start := p.Start()
+	estimates.set(start, p.Heuristic(start))
+	queue := []S{start}
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		reached = append(reached, curr)
+
+		if p.IsGoal(curr) {
+			goals = append(goals, curr)
+		}
+
+		for _, edge := range p.Neighbors(curr) {
+			h, ok := estimates.get(edge.To)
+			if !ok {
+				if maxStates > 0 && estimates.len() >= maxStates {
+					return violations, &LimitError{"explored", maxStates}
+				}
+				h = p.Heuristic(edge.To)
+				estimates.set(edge.To, h)
+				queue = append(queue, edge.To)
+			}
+			moves, _ := predecessors.get(edge.To)
+			predecessors.set(edge.To, append(moves, move{curr, edge.Cost}))
+
+			hc, _ := estimates.get(curr)
+			if excess := hc - (edge.Cost + h); excess > epsilon {
+				violations = append(violations, Violation[S]{Inconsistent, curr, edge.To, hc, excess})
+			}
+		}
+	}
+
+	// Backward Dijkstra from all goal states at once.
+	pq := states[S]{}
+	dist := newStateMap[S, *state[S]](key, 0)
+	for _, goal := range goals {
+		s := &state[S]{state: goal}
+		dist.set(goal, s)
+		heap.Push(&pq, s)
+	}
+	for !pq.Empty() {
+		curr := heap.Pop(&pq).(*state[S])
+		curr.explored = true
+
+		moves, _ := predecessors.get(curr.state)
+		for _, m := range moves {
+			cost := curr.cost + m.cost
+			if s, ok := dist.get(m.from); !ok {
+				s = &state[S]{state: m.from, cost: cost}
+				dist.set(m.from, s)
+				heap.Push(&pq, s)
+			} else if !s.explored && cost < s.cost {
+				s.cost = cost
+				heap.Fix(&pq, s.index)
+			}
+		}
+	}
+
+	// States the goal is not reachable from can have any estimate.
+	// They are reported in the order they have been reached.
+	for _, s := range reached {
+		d, _ := dist.get(s)
+		if h, _ := estimates.get(s); d != nil && h-d.cost > epsilon {
+			violations = append(violations, Violation[S]{Kind: Inadmissible, State: d.state, H: h, Excess: h - d.cost})
+		}
+	}
+
+	return violations, nil
+}